@@ -0,0 +1,64 @@
+package tailf
+
+import "maps"
+
+// Aggregator maintains a live summary of the lines of a tailer, such as
+// counts or histograms, for [WithAggregator]. Observe and Snapshot are
+// never called concurrently, so an Aggregator needs no locking of its
+// own.
+type Aggregator interface {
+	// Observe adds a line to the summary. It is called from the tailing
+	// goroutine and must not block.
+	Observe(l Line)
+
+	// Snapshot returns the current summary. It must not return anything
+	// that later calls to Observe modify.
+	Snapshot() any
+}
+
+// Aggregate returns the current snapshot of the [WithAggregator]
+// aggregator, or nil if there is none. It is safe to call concurrently,
+// also after the tailer has stopped.
+func (t *Tailer) Aggregate() any {
+	t.aggMu.Lock()
+	defer t.aggMu.Unlock()
+	if t.agg == nil {
+		return nil
+	}
+	return t.agg.Snapshot()
+}
+
+// observe hands l to the aggregator, if any.
+func (t *Tailer) observe(l Line) {
+	if t.agg == nil {
+		return
+	}
+	t.aggMu.Lock()
+	defer t.aggMu.Unlock()
+	t.agg.Observe(l)
+}
+
+// CountByLevel is an [Aggregator] that counts lines by the level found
+// by the [WithLevelParser] parser, with lines of no level counted under
+// "". Its snapshot is a map[string]int64. The zero value is ready to
+// use.
+type CountByLevel struct {
+	counts map[string]int64
+}
+
+// Observe counts l under its level.
+func (c *CountByLevel) Observe(l Line) {
+	if c.counts == nil {
+		c.counts = make(map[string]int64)
+	}
+	c.counts[l.Level]++
+}
+
+// Snapshot returns a copy of the counts as a map[string]int64.
+func (c *CountByLevel) Snapshot() any {
+	counts := maps.Clone(c.counts)
+	if counts == nil {
+		counts = make(map[string]int64)
+	}
+	return counts
+}
@@ -0,0 +1,58 @@
+package tailf
+
+import (
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFollowAggregator(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	data := "level=info a\nlevel=warn b\nlevel=info c\nno level\nlevel=info d\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path,
+		WithFromStart(true),
+		WithStopAtEOF(true),
+		WithLevelParser(LogfmtLevel("")),
+		WithAggregator(&CountByLevel{}),
+		WithPrefixDeny("level=info"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tailer.Aggregate(); got == nil {
+		t.Fatal("got nil snapshot")
+	}
+	for range tailer.Lines() {
+	}
+
+	// Lines are counted whether or not they pass the filters.
+	want := map[string]int64{"info": 3, "warn": 1, "": 1}
+	got := tailer.Aggregate().(map[string]int64)
+	if !maps.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// The snapshot is a copy.
+	got["info"] = 0
+	if again := tailer.Aggregate().(map[string]int64); again["info"] != 3 {
+		t.Errorf("snapshot shares the counts: got %v", again)
+	}
+
+	plain, err := Follow(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := plain.Aggregate(); got != nil {
+		t.Errorf("no aggregator: got %v, want nil", got)
+	}
+}
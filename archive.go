@@ -0,0 +1,65 @@
+package tailf
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// openCurrent opens the file the tailer was reading, after its handle
+// was lost: the file at the path, unless that has been replaced and a
+// match of the [WithArchivePattern] pattern is the same file as before.
+func (s *tailState) openCurrent() (*os.File, os.FileInfo, error) {
+	file, info, err := openStat(s.path)
+	if s.o.archivePattern == "" || err == nil && !s.replacedBy(info) {
+		return file, info, err
+	}
+	if f, fi, ok := s.openArchived(); ok {
+		if file != nil {
+			file.Close()
+		}
+		return f, fi, nil
+	}
+	return file, info, err
+}
+
+// openArchived looks for the current file among the matches of the
+// [WithArchivePattern] pattern, by its inode, and opens it.
+func (s *tailState) openArchived() (*os.File, os.FileInfo, bool) {
+	if s.fileID == (fileIdentity{}) {
+		return nil, nil, false
+	}
+	pattern := s.o.archivePattern
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(filepath.Dir(s.path), pattern)
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, nil, false
+	}
+	for _, m := range matches {
+		file, info, err := openStat(m)
+		if err != nil {
+			continue
+		}
+		if !info.Mode().IsRegular() || s.fileID.replacedBy(getFileIdentity(info)) {
+			file.Close()
+			continue
+		}
+		return file, info, true
+	}
+	return nil, nil, false
+}
+
+// openStat opens path and returns it with its stat.
+func openStat(path string) (*os.File, os.FileInfo, error) {
+	file, err := openShared(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	return file, info, nil
+}
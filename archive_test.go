@@ -0,0 +1,113 @@
+package tailf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFollowRotationIntoSubdirectory(t *testing.T) {
+	if !RotationDetectionSupported() {
+		t.Skip("rotation detection not supported")
+	}
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "app.log")
+	archived := filepath.Join(tmp, "archive", "app.log.1")
+
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Dir(archived), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A writer still holding the old file appends after the move; the
+	// tailer drains it through its handle before following the new one.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("old 1\n")
+	if err := os.Rename(path, archived); err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("old 2\n")
+	f.Close()
+	if err := os.WriteFile(path, []byte("new 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"old 1", "old 2", "new 1"} {
+		select {
+		case line := <-tailer.Lines():
+			if line.Text != want {
+				t.Errorf("got %q, want %q", line.Text, want)
+			}
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for %q", want)
+		}
+	}
+
+	cancel()
+	<-tailer.Done()
+}
+
+func TestRecoverFromArchivedFile(t *testing.T) {
+	if !RotationDetectionSupported() {
+		t.Skip("rotation detection not supported")
+	}
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "app.log")
+	archived := filepath.Join(tmp, "archive", "app.log.1")
+
+	if err := os.WriteFile(path, []byte("seen\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Dir(archived), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	o := defaults()
+	o.archivePattern = "archive/app.log.*"
+	s, err := newTailState(path, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.close()
+	offset, err := s.position()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The file is moved and gets more data while the handle is lost.
+	if err := os.Rename(path, archived); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(archived, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("unseen\n")
+	f.Close()
+	if err := os.WriteFile(path, []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if !s.recoverFrom(&TailError{Op: "read", Path: path, Offset: offset}) {
+		t.Fatal("recoverFrom failed")
+	}
+	line, ok, err := s.scan.Scan()
+	if err != nil || !ok || line.Text != "unseen" {
+		t.Errorf("Scan after recovery = %q, %v, %v; want %q from the archived file", line.Text, ok, err, "unseen")
+	}
+}
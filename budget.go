@@ -0,0 +1,113 @@
+package tailf
+
+import (
+	"context"
+	"time"
+)
+
+// OverflowPolicy says what a tailer does with a line it cannot deliver
+// because the Lines channel is full or the memory budget is exhausted.
+// See [WithOverflowPolicy].
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits until the line can be delivered.
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowDrop discards the line.
+	OverflowDrop
+)
+
+// budgetRetry is how often a tailer blocked on the memory budget checks
+// whether the receiver has drained enough lines.
+const budgetRetry = 10 * time.Millisecond
+
+// sentLog records the cumulative text size and the read time of lines
+// sent on the Lines channel, so that the size of the k lines still in the
+// channel, and how long the oldest of them has waited, can be found from
+// the channel's length alone.
+type sentLog struct {
+	cum   []int64     // cum[i%len] is the total after the i-th send
+	read  []time.Time // read[i%len] is the Time of the i-th line sent
+	sends int
+	total int64
+}
+
+func newSentLog(capacity int) sentLog {
+	return sentLog{cum: make([]int64, capacity+1), read: make([]time.Time, capacity+1)}
+}
+
+func (g *sentLog) add(size int, read time.Time) {
+	g.sends++
+	g.total += int64(size)
+	g.cum[g.sends%len(g.cum)] = g.total
+	g.read[g.sends%len(g.read)] = read
+}
+
+// buffered returns the text size of the last k lines sent.
+func (g *sentLog) buffered(k int) int64 {
+	if k <= 0 || len(g.cum) == 0 {
+		return 0
+	}
+	k = min(k, g.sends, len(g.cum)-1)
+	return g.total - g.cum[(g.sends-k)%len(g.cum)]
+}
+
+// oldest returns the read time of the first of the last k lines sent, or
+// the zero time if there are none.
+func (g *sentLog) oldest(k int) time.Time {
+	if k <= 0 || len(g.read) == 0 {
+		return time.Time{}
+	}
+	k = min(k, g.sends, len(g.read)-1)
+	return g.read[(g.sends-k+1)%len(g.read)]
+}
+
+// recordSent accounts for l having been sent on the Lines channel and
+// passes it to the subscribers.
+func (t *Tailer) recordSent(l Line) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.recordSentLocked(l)
+}
+
+// recordSentLocked is recordSent for a caller already holding t.mu.
+func (t *Tailer) recordSentLocked(l Line) {
+	t.stats.Lines++
+	t.sent.add(len(l.Text), l.Time)
+	// The line is already in the channel, so count the room it took.
+	t.fill.add(float64(max(len(t.lines)-1, 0)) / float64(cap(t.lines)))
+	t.publish(l)
+}
+
+// fits reports whether l can be sent without exceeding the memory
+// budget. It also refreshes the count of bytes held by the tailing
+// goroutine itself.
+func (s *tailState) fits(l Line) bool {
+	held := int64(s.pending + s.before.bytes)
+	s.t.mu.Lock()
+	s.t.held = held
+	queued := s.t.sent.buffered(len(s.t.lines))
+	s.t.mu.Unlock()
+
+	if s.o.memoryBudget <= 0 || len(s.t.lines) == 0 {
+		return true
+	}
+	return queued+held+int64(len(l.Text)) <= s.o.memoryBudget
+}
+
+// waitForRoom waits briefly for the receiver to drain lines while
+// serving commands. It returns false if ctx was cancelled.
+func (s *tailState) waitForRoom(ctx context.Context) bool {
+	timer := time.NewTimer(budgetRetry)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case c := <-s.t.cmds:
+		s.run(c)
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,107 @@
+package tailf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSentLogBuffered(t *testing.T) {
+	g := newSentLog(3)
+	for _, size := range []int{1, 2, 4, 8, 16} {
+		g.add(size, time.Unix(int64(size), 0))
+	}
+	tests := []struct {
+		k    int
+		want int64
+	}{
+		{0, 0},
+		{1, 16},
+		{2, 24},
+		{3, 28},
+		{4, 28}, // never more than the channel capacity
+	}
+	for _, tt := range tests {
+		if got := g.buffered(tt.k); got != tt.want {
+			t.Errorf("buffered(%d) = %d, want %d", tt.k, got, tt.want)
+		}
+	}
+	if got := g.oldest(2); got.Unix() != 8 {
+		t.Errorf("oldest(2) = %d, want 8", got.Unix())
+	}
+	if got := g.oldest(0); !got.IsZero() {
+		t.Errorf("oldest(0) = %v, want the zero time", got)
+	}
+}
+
+func writeLines(t *testing.T, path string, n int) {
+	t.Helper()
+	line := strings.Repeat("x", 9) + "\n"
+	if err := os.WriteFile(path, []byte(strings.Repeat(line, n)), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMemoryBudgetBlocks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	writeLines(t, path, 10)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFromStart(true), WithMemoryBudget(30))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Three 9-byte lines fit in the budget; the fourth has to wait.
+	time.Sleep(100 * time.Millisecond)
+	st := tailer.Stats()
+	if st.Lines != 3 || st.BufferedBytes != 27 {
+		t.Errorf("got %d lines, %d bytes buffered; want 3 lines, 27 bytes", st.Lines, st.BufferedBytes)
+	}
+
+	for i := 0; i < 10; i++ {
+		select {
+		case <-tailer.Lines():
+		case <-ctx.Done():
+			t.Fatalf("timed out after %d lines", i)
+		}
+	}
+	if st := tailer.Stats(); st.Dropped != 0 {
+		t.Errorf("Dropped = %d, want 0", st.Dropped)
+	}
+
+	cancel()
+	<-tailer.Done()
+}
+
+func TestMemoryBudgetDrops(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	writeLines(t, path, 10)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFromStart(true),
+		WithMemoryBudget(30), WithOverflowPolicy(OverflowDrop))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-tailer.CaughtUp():
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+	st := tailer.Stats()
+	if st.Lines != 3 || st.Dropped != 7 {
+		t.Errorf("got %d lines, %d dropped; want 3 lines, 7 dropped", st.Lines, st.Dropped)
+	}
+
+	cancel()
+	<-tailer.Done()
+}
@@ -0,0 +1,162 @@
+package tailf
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrStopped is returned by [Tailer] methods that need the tailing
+// goroutine when the tailer has already stopped.
+var ErrStopped = errors.New("tailf: tailer stopped")
+
+// command is a request to run fn on the tailing goroutine, which owns
+// all of the tailer's file state.
+type command struct {
+	fn   func(*tailState) error
+	done chan error
+}
+
+// do runs fn on the tailing goroutine and waits for its result. The
+// tailing goroutine serves no commands once it closes t.stopping, so a
+// helper goroutine can call do without holding up the stop.
+func (t *Tailer) do(fn func(*tailState) error) error {
+	c := command{fn: fn, done: make(chan error, 1)}
+	select {
+	case t.cmds <- c:
+		return <-c.done
+	case <-t.stopping:
+		return ErrStopped
+	}
+}
+
+// run executes a command received by the tailing goroutine.
+func (s *tailState) run(c command) {
+	c.done <- c.fn(s)
+}
+
+// JumpToEnd skips everything not yet delivered and resumes following at
+// the current end of the file, as a live viewer's "jump to now" would.
+// Lines already buffered in the [Tailer.Lines] channel but not yet
+// received are discarded, as is any incomplete trailing line.
+//
+// It returns [ErrStopped] if the tailer is no longer running.
+func (t *Tailer) JumpToEnd() error {
+	return t.do(func(s *tailState) error {
+		return s.jumpToEnd()
+	})
+}
+
+func (s *tailState) jumpToEnd() error {
+	if _, err := s.file.Seek(0, io.SeekEnd); err != nil {
+		return s.fail("seek", err)
+	}
+	s.resetReader()
+	s.pending = 0
+	s.before.reset()
+	s.afterLeft = 0
+	s.epoch++
+	if s.reordering != nil {
+		s.reordering.reset()
+	}
+	if s.spill != nil {
+		s.spill.reset()
+		s.spillStats()
+	}
+
+	for {
+		select {
+		case <-s.t.lines:
+		default:
+			return nil
+		}
+	}
+}
+
+// Pause stops the tailer from reading the file and delivering lines,
+// keeping its handle and position. The file may keep growing while the
+// tailer is paused; the backlog is read after [Tailer.Resume]. A line
+// that was already being delivered when Pause was called is held back
+// until then. Pausing a paused tailer has no effect.
+//
+// It returns [ErrStopped] if the tailer is no longer running.
+func (t *Tailer) Pause() error {
+	return t.do(func(s *tailState) error {
+		if !s.paused {
+			s.paused = true
+			s.announce(LifecycleEvent{Type: LifecyclePaused})
+		}
+		return nil
+	})
+}
+
+// Resume continues a tailer stopped by [Tailer.Pause]. If the tailer was
+// paused at the end of the file, the file is checked for truncation and
+// rotation right away, since either may have happened in the meantime;
+// otherwise that check happens as usual once the backlog has been read.
+// Resuming a running tailer has no effect.
+//
+// It returns [ErrStopped] if the tailer is no longer running.
+func (t *Tailer) Resume() error {
+	return t.do(func(s *tailState) error {
+		if !s.paused {
+			return nil
+		}
+		s.paused = false
+		s.announce(LifecycleEvent{Type: LifecycleResumed})
+		if !s.atEOF {
+			return nil
+		}
+		_, err := s.checkFileState()
+		return err
+	})
+}
+
+// SetNotify makes the tailer wake up when ch fires instead of on the
+// notification channels it had, as if it had been created with
+// [WithNotify](ch) and no [WithNotifyChannels]. The poll interval stays
+// as a fallback. A nil ch reverts to pure polling, as [Tailer.SetPolling]
+// does. The file is read right after the switch, so data whose
+// notification went to the old channel is not left waiting. It has no
+// effect while [WithManualPoll] is in force.
+//
+// It returns [ErrStopped] if the tailer is no longer running.
+func (t *Tailer) SetNotify(ch <-chan struct{}) error {
+	return t.do(func(s *tailState) error {
+		s.o.notify = ch
+		return nil
+	})
+}
+
+// SetPolling drops the tailer's notification channels, so that it only
+// checks the file every poll interval. It is SetNotify(nil).
+func (t *Tailer) SetPolling() error {
+	return t.SetNotify(nil)
+}
+
+// SetPollInterval changes how often the tailer checks the file when no
+// notification arrives, as [WithPollInterval] does, starting with the
+// next wait.
+//
+// It returns [ErrStopped] if the tailer is no longer running.
+func (t *Tailer) SetPollInterval(d time.Duration) error {
+	return t.do(func(s *tailState) error {
+		s.o.pollInterval = max(d, minPollInterval)
+		return nil
+	})
+}
+
+// waitWhilePaused serves commands until the tailer is resumed. It
+// returns false if ctx was cancelled first.
+func (s *tailState) waitWhilePaused(ctx context.Context) bool {
+	for s.paused {
+		select {
+		case c := <-s.t.cmds:
+			s.run(c)
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,237 @@
+package tailf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJumpToEnd(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	// More backlog than the Lines channel can buffer, so the tailer is
+	// blocked delivering when the jump happens.
+	var b strings.Builder
+	for i := 0; i < 500; i++ {
+		fmt.Fprintf(&b, "backlog %d\n", i)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFromStart(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if err := tailer.JumpToEnd(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("fresh\n")
+	f.Close()
+
+	select {
+	case line := <-tailer.Lines():
+		if line.Text != "fresh" {
+			t.Errorf("got %q, want %q", line.Text, "fresh")
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for line after jump")
+	}
+
+	cancel()
+	<-tailer.Done()
+
+	if err := tailer.JumpToEnd(); !errors.Is(err, ErrStopped) {
+		t.Errorf("JumpToEnd after stop: got %v, want ErrStopped", err)
+	}
+}
+
+func TestPauseResume(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("before\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFromStart(true), WithPollInterval(20*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case line := <-tailer.Lines():
+		if line.Text != "before" {
+			t.Errorf("got %q, want %q", line.Text, "before")
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for first line")
+	}
+
+	// Pausing twice is harmless.
+	for i := 0; i < 2; i++ {
+		if err := tailer.Pause(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("during\n")
+	f.Close()
+
+	time.Sleep(200 * time.Millisecond)
+	select {
+	case line := <-tailer.Lines():
+		t.Fatalf("received %q while paused", line.Text)
+	default:
+	}
+
+	if err := tailer.Resume(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case line := <-tailer.Lines():
+		if line.Text != "during" {
+			t.Errorf("got %q, want %q", line.Text, "during")
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for backlog after resume")
+	}
+
+	cancel()
+	<-tailer.Done()
+}
+
+func TestResumeDetectsRotationWhilePaused(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFromStart(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-tailer.Lines()
+	<-tailer.CaughtUp()
+
+	if err := tailer.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := tailer.Resume(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := tailer.Stats().Rotations; got != 1 {
+		t.Errorf("Rotations after resume = %d, want 1", got)
+	}
+	select {
+	case line := <-tailer.Lines():
+		if line.Text != "new" {
+			t.Errorf("got %q, want %q", line.Text, "new")
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for line from rotated file")
+	}
+
+	cancel()
+	<-tailer.Done()
+}
+
+func TestSetNotify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The poll interval is too long to matter, so lines arrive only on
+	// notifications, commands or, after SetPollInterval, polls.
+	first := make(chan struct{}, 1)
+	tailer, err := Follow(ctx, path, WithNotify(first), WithPollInterval(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-tailer.CaughtUp()
+	appendLine(t, path, "on first")
+	first <- struct{}{}
+	if got := nextLine(ctx, t, tailer); got != "on first" {
+		t.Fatalf("got %q, want %q", got, "on first")
+	}
+
+	// A line whose notification would have gone to the old channel is
+	// read at the switch.
+	appendLine(t, path, "at switch")
+	second := make(chan struct{}, 1)
+	if err := tailer.SetNotify(second); err != nil {
+		t.Fatal(err)
+	}
+	if got := nextLine(ctx, t, tailer); got != "at switch" {
+		t.Fatalf("got %q, want %q", got, "at switch")
+	}
+
+	appendLine(t, path, "on second")
+	first <- struct{}{}
+	select {
+	case l := <-tailer.Lines():
+		t.Fatalf("woken by the old channel: got %q", l.Text)
+	case <-time.After(50 * time.Millisecond):
+	}
+	second <- struct{}{}
+	if got := nextLine(ctx, t, tailer); got != "on second" {
+		t.Fatalf("got %q, want %q", got, "on second")
+	}
+
+	if err := tailer.SetPolling(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tailer.SetPollInterval(10 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	appendLine(t, path, "polled")
+	if got := nextLine(ctx, t, tailer); got != "polled" {
+		t.Fatalf("got %q, want %q", got, "polled")
+	}
+
+	cancel()
+	<-tailer.Done()
+	if err := tailer.SetPolling(); !errors.Is(err, ErrStopped) {
+		t.Errorf("SetPolling after stop: got %v, want ErrStopped", err)
+	}
+}
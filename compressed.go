@@ -0,0 +1,93 @@
+package tailf
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+)
+
+// successor is the gzip-compressed successor of the file a resume token
+// was taken from, positioned at the token's offset in its decompressed
+// content, for [WithCompressedResume].
+type successor struct {
+	file *os.File
+	r    *bufio.Reader
+}
+
+// openSuccessor opens the compressed successor of path named by the
+// [WithCompressedResume] function and skips to the offset of the
+// [WithResumeToken] token. It returns nil if there is no such file, or
+// if its content is shorter than the offset, which means it is not the
+// file the token was taken from.
+func openSuccessor(path string, o options) *successor {
+	if o.compressedResume == nil || o.resumeToken.IsZero() {
+		return nil
+	}
+	name := o.compressedResume(path)
+	if name == "" {
+		return nil
+	}
+	file, err := openShared(name)
+	if err != nil {
+		return nil
+	}
+	zr, err := gzip.NewReader(bufio.NewReader(file))
+	if err != nil {
+		file.Close()
+		return nil
+	}
+	if _, err := io.CopyN(io.Discard, zr, o.resumeToken.offset); err != nil {
+		file.Close()
+		return nil
+	}
+	return &successor{file: file, r: bufio.NewReaderSize(zr, o.bufSize)}
+}
+
+// useSuccessor arranges for succ to be read before the file at the path,
+// which is the file that replaced the one the token was taken from, and
+// so is read from the start.
+func (s *tailState) useSuccessor(succ *successor) {
+	s.succ = succ
+	s.generation = s.o.resumeToken.generation
+	s.seqBase = s.o.resumeToken.seq
+}
+
+// replaySuccessor delivers the rest of the compressed successor, ending
+// with its last line even if that is incomplete, and closes it. It
+// returns false if ctx was cancelled.
+func (s *tailState) replaySuccessor(ctx context.Context) bool {
+	succ := s.succ
+	if succ == nil {
+		return true
+	}
+	defer func() {
+		succ.file.Close()
+		s.succ = nil
+	}()
+
+	sc := lineScanner{r: succ.r, o: &s.o, rotationIndex: s.scan.rotationIndex}
+	for {
+		l, ok, err := sc.Scan()
+		if err != nil {
+			// A damaged archive ends the replay; the live file follows.
+			break
+		}
+		if !ok {
+			if payload := sc.partialPayload(); len(payload) > 0 {
+				l := sc.line(payload, nil)
+				l.Partial = true
+				if !s.deliver(ctx, l) {
+					return false
+				}
+			}
+			break
+		}
+		if !s.deliver(ctx, l) {
+			return false
+		}
+	}
+	s.generation++
+	return true
+}
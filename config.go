@@ -0,0 +1,330 @@
+package tailf
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// Config holds the tailer settings that can be written down, for
+// programs that configure tailers declaratively, for example from a JSON
+// or YAML file. Each field corresponds to the option of the same name;
+// zero values leave the option's default in place. Options that take
+// functions or channels, such as [WithFilter] or [WithNotify], have no
+// field and can be passed to [FollowConfig] alongside the Config.
+type Config struct {
+	FromStart           bool     `json:"from_start,omitempty"`
+	StartAtPercent      *float64 `json:"start_at_percent,omitempty"`
+	PollInterval        Duration `json:"poll_interval,omitempty"`
+	BufSize             int      `json:"buf_size,omitempty"`
+	InBandMarkers       bool     `json:"in_band_markers,omitempty"`
+	StuckReopen         *int     `json:"stuck_reopen,omitempty"`
+	StrictCRLF          bool     `json:"strict_crlf,omitempty"`
+	ThroughputWindow    Duration `json:"throughput_window,omitempty"`
+	FallbackPaths       []string `json:"fallback_paths,omitempty"`
+	ContextBefore       int      `json:"context_before,omitempty"`
+	ContextAfter        int      `json:"context_after,omitempty"`
+	Mmap                bool     `json:"mmap,omitempty"`
+	RotationCooldown    Duration `json:"rotation_cooldown,omitempty"`
+	ExpandTabs          int      `json:"expand_tabs,omitempty"`
+	MemoryBudget        int64    `json:"memory_budget,omitempty"`
+	RestartOnShrink     bool     `json:"restart_on_shrink,omitempty"`
+	StopAtEOF           bool     `json:"stop_at_eof,omitempty"`
+	RequireFinalNewline bool     `json:"require_final_newline,omitempty"`
+	GzipInput           bool     `json:"gzip_input,omitempty"`
+	ReadDeadline        Duration `json:"read_deadline,omitempty"`
+	NotifyCoalesce      Duration `json:"notify_coalesce,omitempty"`
+	StripPrefix         int      `json:"strip_prefix,omitempty"`
+	FollowByName        bool     `json:"follow_by_name,omitempty"`
+	PathTruncationCheck bool     `json:"path_truncation_check,omitempty"`
+	LineTimeout         Duration `json:"line_timeout,omitempty"`
+	CaughtUpStable      Duration `json:"caught_up_stable,omitempty"`
+	ReadTimeout         Duration `json:"read_timeout,omitempty"`
+	DedupRecent         int      `json:"dedup_recent,omitempty"`
+	SkipHeaderBytes     int64    `json:"skip_header_bytes,omitempty"`
+	PrefixAllow         []string `json:"prefix_allow,omitempty"`
+	PrefixDeny          []string `json:"prefix_deny,omitempty"`
+	FixedRecordSize     int      `json:"fixed_record_size,omitempty"`
+	InitialBytes        int64    `json:"initial_bytes,omitempty"`
+	MaxFileSize         int64    `json:"max_file_size,omitempty"`
+	WriterMmap          bool     `json:"writer_mmap,omitempty"`
+	Debounce            Duration `json:"debounce,omitempty"`
+	FromStartOnce       bool     `json:"from_start_once,omitempty"`
+	TextDecoding        bool     `json:"text_decoding,omitempty"`
+	AppendOnly          bool     `json:"append_only,omitempty"`
+	RescanInterval      Duration `json:"rescan_interval,omitempty"`
+
+	// RotationDrainTimeout is the bound of [WithRotationDrainTimeout];
+	// a negative value such as "-1s" drains without a bound.
+	RotationDrainTimeout Duration `json:"rotation_drain_timeout,omitempty"`
+
+	// Delimiters holds the bytes of [WithDelimiters], such as "\n\x1e".
+	Delimiters string `json:"delimiters,omitempty"`
+
+	// Overflow is "block" or "drop", for [OverflowBlock] and
+	// [OverflowDrop]. Empty means block.
+	Overflow string `json:"overflow,omitempty"`
+
+	// NoRotationDetection disables rotation detection, as
+	// WithRotationDetection(false) does.
+	NoRotationDetection bool `json:"no_rotation_detection,omitempty"`
+
+	// NoFollowSymlink resolves a symbolic link once, as
+	// WithFollowSymlink(false) does.
+	NoFollowSymlink bool `json:"no_follow_symlink,omitempty"`
+
+	// NoAlignToLine delivers the torn first line of a start inside a
+	// line, as WithAlignToLine(false) does.
+	NoAlignToLine bool `json:"no_align_to_line,omitempty"`
+
+	Spill       *Spill    `json:"spill,omitempty"`
+	Supervise   *Restarts `json:"supervise,omitempty"`
+	FilterChain *Filters  `json:"filter_chain,omitempty"`
+}
+
+// Spill holds the arguments of [WithSpillToDisk].
+type Spill struct {
+	Dir      string `json:"dir,omitempty"`
+	MaxBytes int64  `json:"max_bytes,omitempty"`
+}
+
+// Restarts holds the arguments of [WithSupervise].
+type Restarts struct {
+	Delay Duration `json:"delay,omitempty"`
+	Max   int      `json:"max,omitempty"`
+}
+
+// Filters holds the arguments of [WithFilterChain].
+type Filters struct {
+	Rules       []FilterSpec `json:"rules,omitempty"`
+	DefaultDeny bool         `json:"default_deny,omitempty"`
+}
+
+// FilterSpec is a [FilterRule] written down. Action is "allow" or
+// "deny", and exactly one of Prefix, for [AllowPrefix] and [DenyPrefix],
+// and Regexp, for [Allow] and [Deny], is set.
+type FilterSpec struct {
+	Action string `json:"action"`
+	Prefix string `json:"prefix,omitempty"`
+	Regexp string `json:"regexp,omitempty"`
+}
+
+// rule returns the rule that f stands for.
+func (f FilterSpec) rule() (FilterRule, error) {
+	var r FilterRule
+	switch f.Action {
+	case "allow":
+	case "deny":
+		r.deny = true
+	default:
+		return r, fmt.Errorf("tailf: unknown filter action %q", f.Action)
+	}
+	switch {
+	case f.Prefix != "" && f.Regexp != "":
+		return r, fmt.Errorf("tailf: filter rule with both prefix %q and regexp %q", f.Prefix, f.Regexp)
+	case f.Regexp != "":
+		re, err := regexp.Compile(f.Regexp)
+		if err != nil {
+			return r, fmt.Errorf("tailf: filter rule: %w", err)
+		}
+		r.re = re
+	case f.Prefix != "":
+		r.prefix = f.Prefix
+	default:
+		return r, fmt.Errorf("tailf: filter rule without prefix or regexp")
+	}
+	return r, nil
+}
+
+// Duration is a [time.Duration] written as a string such as "250ms" in
+// JSON and other text formats.
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(b []byte) error {
+	v, err := time.ParseDuration(string(b))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Options returns the options that c stands for. It fails if a field
+// holds a value that no option accepts.
+func (c Config) Options() ([]Option, error) {
+	var opts []Option
+	add := func(o Option) { opts = append(opts, o) }
+
+	if c.FromStart {
+		add(WithFromStart(true))
+	}
+	if c.StartAtPercent != nil {
+		add(WithStartAtPercent(*c.StartAtPercent))
+	}
+	if c.PollInterval != 0 {
+		add(WithPollInterval(time.Duration(c.PollInterval)))
+	}
+	if c.BufSize != 0 {
+		add(WithBufSize(c.BufSize))
+	}
+	if c.InBandMarkers {
+		add(WithInBandMarkers(true))
+	}
+	if c.StuckReopen != nil {
+		add(WithStuckReopen(*c.StuckReopen))
+	}
+	if c.StrictCRLF {
+		add(WithStrictCRLF(true))
+	}
+	if c.ThroughputWindow != 0 {
+		add(WithThroughputWindow(time.Duration(c.ThroughputWindow)))
+	}
+	if len(c.FallbackPaths) > 0 {
+		add(WithFallbackPaths(c.FallbackPaths...))
+	}
+	if c.ContextBefore != 0 || c.ContextAfter != 0 {
+		add(WithContextLines(c.ContextBefore, c.ContextAfter))
+	}
+	if c.Mmap {
+		add(WithMmap(true))
+	}
+	if c.RotationCooldown != 0 {
+		add(WithRotationCooldown(time.Duration(c.RotationCooldown)))
+	}
+	if c.ExpandTabs != 0 {
+		add(WithExpandTabs(c.ExpandTabs))
+	}
+	if c.MemoryBudget != 0 {
+		add(WithMemoryBudget(c.MemoryBudget))
+	}
+	switch c.Overflow {
+	case "", "block":
+	case "drop":
+		add(WithOverflowPolicy(OverflowDrop))
+	default:
+		return nil, fmt.Errorf("tailf: unknown overflow policy %q", c.Overflow)
+	}
+	if c.RestartOnShrink {
+		add(WithRestartOnShrink(true))
+	}
+	if c.StopAtEOF {
+		add(WithStopAtEOF(true))
+	}
+	if c.RequireFinalNewline {
+		add(WithRequireFinalNewline(true))
+	}
+	if c.GzipInput {
+		add(WithGzipInput(true))
+	}
+	if c.ReadDeadline != 0 {
+		add(WithReadDeadline(time.Duration(c.ReadDeadline)))
+	}
+	if c.NotifyCoalesce != 0 {
+		add(WithNotifyCoalesce(time.Duration(c.NotifyCoalesce)))
+	}
+	if c.FollowByName {
+		add(WithFollowByName(true))
+	}
+	if c.Delimiters != "" {
+		add(WithDelimiters([]byte(c.Delimiters)...))
+	}
+	if c.ReadTimeout != 0 {
+		add(WithReadTimeout(time.Duration(c.ReadTimeout)))
+	}
+	if c.DedupRecent != 0 {
+		add(WithDedupRecent(c.DedupRecent))
+	}
+	if c.SkipHeaderBytes != 0 {
+		add(WithSkipHeaderBytes(c.SkipHeaderBytes))
+	}
+	if len(c.PrefixAllow) > 0 {
+		add(WithPrefixAllow(c.PrefixAllow...))
+	}
+	if len(c.PrefixDeny) > 0 {
+		add(WithPrefixDeny(c.PrefixDeny...))
+	}
+	if c.FilterChain != nil {
+		chain := FilterChain{DefaultDeny: c.FilterChain.DefaultDeny}
+		for _, f := range c.FilterChain.Rules {
+			r, err := f.rule()
+			if err != nil {
+				return nil, err
+			}
+			chain.Rules = append(chain.Rules, r)
+		}
+		add(WithFilterChain(chain))
+	}
+	if c.FixedRecordSize != 0 {
+		add(WithFixedRecordSize(c.FixedRecordSize))
+	}
+	if c.InitialBytes != 0 {
+		add(WithInitialBytes(c.InitialBytes))
+	}
+	if c.MaxFileSize != 0 {
+		add(WithMaxFileSize(c.MaxFileSize))
+	}
+	if c.WriterMmap {
+		add(WithWriterMmap(true))
+	}
+	if c.RescanInterval != 0 {
+		add(WithRescanInterval(time.Duration(c.RescanInterval)))
+	}
+	if c.AppendOnly {
+		add(WithAppendOnly(true))
+	}
+	if c.TextDecoding {
+		add(WithTextDecoding(true))
+	}
+	if c.RotationDrainTimeout != 0 {
+		add(WithRotationDrainTimeout(time.Duration(c.RotationDrainTimeout)))
+	}
+	if c.FromStartOnce {
+		add(WithFromStartOnce(true))
+	}
+	if c.Debounce != 0 {
+		add(WithDebounce(time.Duration(c.Debounce)))
+	}
+	if c.CaughtUpStable != 0 {
+		add(WithCaughtUpStable(time.Duration(c.CaughtUpStable)))
+	}
+	if c.LineTimeout != 0 {
+		add(WithLineTimeout(time.Duration(c.LineTimeout)))
+	}
+	if c.PathTruncationCheck {
+		add(WithPathTruncationCheck(true))
+	}
+	if c.StripPrefix != 0 {
+		add(WithStripPrefix(c.StripPrefix))
+	}
+	if c.NoRotationDetection {
+		add(WithRotationDetection(false))
+	}
+	if c.NoFollowSymlink {
+		add(WithFollowSymlink(false))
+	}
+	if c.NoAlignToLine {
+		add(WithAlignToLine(false))
+	}
+	if c.Spill != nil {
+		add(WithSpillToDisk(c.Spill.Dir, c.Spill.MaxBytes))
+	}
+	if c.Supervise != nil {
+		add(WithSupervise(time.Duration(c.Supervise.Delay), c.Supervise.Max))
+	}
+	return opts, nil
+}
+
+// FollowConfig is [Follow] configured by cfg. Any opts are applied
+// after the options derived from cfg, and take precedence over them.
+func FollowConfig(ctx context.Context, path string, cfg Config, opts ...Option) (*Tailer, error) {
+	cfgOpts, err := cfg.Options()
+	if err != nil {
+		return nil, err
+	}
+	return Follow(ctx, path, append(cfgOpts, opts...)...)
+}
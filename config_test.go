@@ -0,0 +1,99 @@
+package tailf
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConfigJSONRoundTrip(t *testing.T) {
+	const input = `{
+		"from_start": true,
+		"poll_interval": "250ms",
+		"buf_size": 8192,
+		"stuck_reopen": 0,
+		"context_before": 2,
+		"overflow": "drop",
+		"spill": {"dir": "/var/spool/tailf", "max_bytes": 1048576},
+		"supervise": {"delay": "1s", "max": 3}
+	}`
+
+	var cfg Config
+	if err := json.Unmarshal([]byte(input), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	out, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again Config
+	if err := json.Unmarshal(out, &again); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg, again) {
+		t.Errorf("round trip changed config:\n%+v\n%+v", cfg, again)
+	}
+
+	opts, err := cfg.Options()
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := defaults()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if !o.fromStart || o.pollInterval != 250*time.Millisecond || o.bufSize != 8192 {
+		t.Errorf("basic options not applied: %+v", o)
+	}
+	if o.stuckPolls != 0 || o.contextBefore != 2 || o.overflow != OverflowDrop {
+		t.Errorf("stuck_reopen, context_before or overflow not applied: %+v", o)
+	}
+	if !o.spill || o.spillDir != "/var/spool/tailf" || o.spillMax != 1<<20 {
+		t.Errorf("spill not applied: %+v", o)
+	}
+	if o.restartDelay != time.Second || o.maxRestarts != 3 {
+		t.Errorf("supervise not applied: %+v", o)
+	}
+	if o.throughputWindow != 5*time.Second || !o.detectRotation {
+		t.Errorf("defaults not kept: %+v", o)
+	}
+}
+
+func TestConfigInvalid(t *testing.T) {
+	if _, err := (Config{Overflow: "spill"}).Options(); err == nil {
+		t.Error("expected an error for an unknown overflow policy")
+	}
+	var d Duration
+	if err := json.Unmarshal([]byte(`"soon"`), &d); err == nil {
+		t.Error("expected an error for an invalid duration")
+	}
+}
+
+func TestFollowConfig(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("one\ntwo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tailer, err := FollowConfig(ctx, path, Config{FromStart: true, StopAtEOF: true},
+		WithTransform(func(s string) string { return s + "!" }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for line := range tailer.Lines() {
+		got = append(got, line.Text)
+	}
+	if want := []string{"one!", "two!"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
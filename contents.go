@@ -0,0 +1,197 @@
+package tailf
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// settleInterval is how soon [FollowContents] reads a file again that
+// changed while it was being read.
+const settleInterval = 10 * time.Millisecond
+
+// FollowContents watches a small file that is rewritten rather than
+// appended to, such as a status or health file, and delivers its whole
+// contents on the returned channel each time they change. The first
+// payload is the contents at the start; each later one is the file as
+// read after a change. Each payload is a new slice that the receiver may
+// keep.
+//
+// A change is noticed when the file's size, modification time or
+// identity differs from the last read, so a file replaced by a rename is
+// seen as well as one rewritten in place. A read during which the file
+// changed is discarded and made again, and an empty file, as left
+// between the truncation and the write of a rewrite in place, is only
+// delivered once it has stayed empty for a poll interval. A writer that
+// rewrites the file in several steps, pausing between them, can still be
+// caught halfway; with [WithDebounce] a change is only read once the
+// file has stayed the same for the debounce period, which avoids that. A
+// read that yields the same contents as the last payload, as after a
+// touch, is not delivered again. A file that goes missing is waited for,
+// its last contents standing until it returns.
+//
+// With [WithTextDecoding], each payload is converted to UTF-8 text with
+// "\n" line endings, as needed for the text exports of Windows tools;
+// [SplitLines] then splits it into lines.
+//
+// The file is checked every poll interval, and when a [WithNotify] or
+// [WithNotifyChannels] channel fires. [WithFollowSymlink] and
+// [WithFollowByName] apply as for [Follow]; other options have no
+// effect. It fails if path cannot be read, unless [WithFollowByName] is
+// set and the file does not exist yet. The channel is closed when ctx is
+// cancelled.
+func FollowContents(ctx context.Context, path string, opts ...Option) (<-chan []byte, error) {
+	o := defaults()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	path = resolvePath(path, o)
+	info, err := os.Stat(path)
+	switch {
+	case err == nil && info.IsDir():
+		return nil, fmt.Errorf("tailf: %w: %s", ErrIsDirectory, path)
+	case err != nil && !(o.followByName && errors.Is(err, fs.ErrNotExist)):
+		return nil, fmt.Errorf("tailf: %w", err)
+	}
+
+	out := make(chan []byte, 1)
+	go watchContents(ctx, path, o, out)
+	return out, nil
+}
+
+// watchContents runs the loop behind [FollowContents], sending each new
+// version of the file's contents to out until ctx is done.
+func watchContents(ctx context.Context, path string, o options, out chan<- []byte) {
+	defer close(out)
+	stop := make(chan struct{})
+	defer close(stop)
+	notify := mergeNotify(ctx, stop, o.notify, o.notifyChans)
+
+	var (
+		read      os.FileInfo // the file as last read
+		pending   os.FileInfo // a change not yet read, under debounce
+		changedAt time.Time
+		last      []byte
+		delivered bool
+	)
+	timer := time.NewTimer(o.pollInterval)
+	defer timer.Stop()
+	for {
+		wait := o.pollInterval
+		info, err := os.Stat(path)
+		if err == nil && info.Mode().IsRegular() && !sameContents(info, read) {
+			if !sameContents(info, pending) {
+				pending, changedAt = info, time.Now()
+			}
+			quiet := time.Since(changedAt)
+			switch {
+			case delivered && quiet < o.debounce:
+				wait = min(wait, o.debounce-quiet)
+			case info.Size() == 0 && quiet < o.pollInterval:
+				// A rewrite in place empties the file before writing
+				// it, so an empty file has to stay empty for a poll.
+				wait = min(wait, o.pollInterval-quiet)
+			default:
+				data, err := os.ReadFile(path)
+				if err != nil {
+					break
+				}
+				if after, err := os.Stat(path); err != nil || !sameContents(info, after) {
+					// Changed while being read: read it again once
+					// the writer is done.
+					wait = min(wait, settleInterval)
+					break
+				}
+				read, pending = info, nil
+				if o.textDecoding {
+					data = decodeText(data)
+				}
+				if !delivered || !bytes.Equal(data, last) {
+					select {
+					case out <- data:
+					case <-ctx.Done():
+						return
+					}
+					last, delivered = data, true
+				}
+			}
+		}
+
+		timer.Reset(wait)
+		select {
+		case <-timer.C:
+		case <-notify:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sameContents reports whether a and b describe the same version of the
+// same file, as far as its size, modification time and identity tell.
+func sameContents(a, b os.FileInfo) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	return a.Size() == b.Size() && a.ModTime().Equal(b.ModTime()) && os.SameFile(a, b)
+}
+
+// decodeText converts data to UTF-8 with "\n" line endings for
+// [WithTextDecoding]. A byte order mark selects UTF-16 in either byte
+// order, or UTF-8, and is dropped; without one, data whose first
+// character is ASCII encoded as UTF-16LE is taken to be UTF-16LE, and
+// anything else to be UTF-8 already. An odd byte at the end of UTF-16
+// data is dropped.
+func decodeText(data []byte) []byte {
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		order, data = binary.LittleEndian, data[2:]
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		order, data = binary.BigEndian, data[2:]
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		data = data[3:]
+	case len(data) >= 2 && data[0] != 0 && data[0] < utf8.RuneSelf && data[1] == 0:
+		order = binary.LittleEndian
+	}
+	if order != nil {
+		units := make([]uint16, len(data)/2)
+		for i := range units {
+			units[i] = order.Uint16(data[2*i:])
+		}
+		text := make([]byte, 0, len(units))
+		for _, r := range utf16.Decode(units) {
+			text = utf8.AppendRune(text, r)
+		}
+		data = text
+	}
+	return bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+}
+
+// SplitLines splits the text of a [FollowContents] payload into lines the
+// way the default framer does: at each '\n', with trailing '\r' and '\n'
+// stripped and empty lines skipped. A last line without a newline is
+// included.
+func SplitLines(data []byte) []string {
+	var lines []string
+	for len(data) > 0 {
+		line := data
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			line, data = data[:i], data[i+1:]
+		} else {
+			data = nil
+		}
+		if line = bytes.TrimRight(line, "\r"); len(line) > 0 {
+			lines = append(lines, string(line))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,202 @@
+package tailf
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+func TestFollowContents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "health")
+	if err := os.WriteFile(path, []byte("starting\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	contents, err := FollowContents(ctx, path, WithPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := func() string {
+		t.Helper()
+		select {
+		case data := <-contents:
+			return string(data)
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for contents")
+			return ""
+		}
+	}
+	if got := next(); got != "starting\n" {
+		t.Fatalf("got %q, want %q", got, "starting\n")
+	}
+
+	// Rewritten in place, to a shorter text.
+	if err := os.WriteFile(path, []byte("ok\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := next(); got != "ok\n" {
+		t.Fatalf("got %q, want %q", got, "ok\n")
+	}
+
+	// A touch leaves the contents as they were and is not delivered.
+	later := time.Now().Add(time.Second)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	// Replaced by a rename.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte("degraded\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+	if got := next(); got != "degraded\n" {
+		t.Fatalf("got %q, want %q", got, "degraded\n")
+	}
+
+	cancel()
+	for range contents {
+	}
+}
+
+func TestFollowContentsDebounce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "status")
+	if err := os.WriteFile(path, []byte("0"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	contents, err := FollowContents(ctx, path,
+		WithPollInterval(5*time.Millisecond),
+		WithDebounce(200*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(<-contents); got != "0" {
+		t.Fatalf("got %q, want %q", got, "0")
+	}
+
+	// Rewrites closer together than the debounce period are delivered
+	// once, as the last of them.
+	for _, text := range []string{"1", "22", "333", "4444"} {
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if got := string(<-contents); got != "4444" {
+		t.Errorf("got %q, want %q", got, "4444")
+	}
+	select {
+	case data := <-contents:
+		t.Errorf("got extra contents %q", data)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestFollowContentsMissing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "status")
+	if _, err := FollowContents(context.Background(), path); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("got %v, want fs.ErrNotExist", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	contents, err := FollowContents(ctx, path, WithFollowByName(true), WithPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("up\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(<-contents); got != "up\n" {
+		t.Errorf("got %q, want %q", got, "up\n")
+	}
+}
+
+// utf16Bytes encodes s as UTF-16, little-endian unless be is set.
+func utf16Bytes(s string, be bool) []byte {
+	var b []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		if be {
+			b = append(b, byte(u>>8), byte(u))
+		} else {
+			b = append(b, byte(u), byte(u>>8))
+		}
+	}
+	return b
+}
+
+func TestDecodeText(t *testing.T) {
+	const text = "Ereignis 1\r\nÜberlauf 😀\r\n"
+	const want = "Ereignis 1\nÜberlauf 😀\n"
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"utf-16le bom", append([]byte{0xFF, 0xFE}, utf16Bytes(text, false)...)},
+		{"utf-16be bom", append([]byte{0xFE, 0xFF}, utf16Bytes(text, true)...)},
+		{"utf-16le", utf16Bytes(text, false)},
+		{"utf-16le odd byte", append(utf16Bytes(text, false), 'x')},
+		{"utf-8 bom", append([]byte{0xEF, 0xBB, 0xBF}, text...)},
+		{"utf-8", []byte(text)},
+	}
+	for _, tt := range tests {
+		if got := string(decodeText(tt.data)); got != want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, want)
+		}
+	}
+}
+
+func TestSplitLines(t *testing.T) {
+	got := SplitLines([]byte("a\r\n\nb\r\r\nc"))
+	if want := []string{"a", "b", "c"}; !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := SplitLines(nil); len(got) != 0 {
+		t.Errorf("empty: got %q", got)
+	}
+}
+
+func TestFollowContentsTextDecoding(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.txt")
+	export := func(text string) {
+		t.Helper()
+		data := append([]byte{0xFF, 0xFE}, utf16Bytes(text, false)...)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	export("Information\tStart\r\n")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	contents, err := FollowContents(ctx, path, WithTextDecoding(true), WithPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := SplitLines(<-contents); !slices.Equal(got, []string{"Information\tStart"}) {
+		t.Errorf("got %q", got)
+	}
+
+	export("Information\tStart\r\nWarning\tDisk\r\n")
+	if got := SplitLines(<-contents); !slices.Equal(got, []string{"Information\tStart", "Warning\tDisk"}) {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,84 @@
+package tailf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FollowDaily tails the current one of a series of dated files in dir,
+// such as "app-2024-05-01.log", whose names are given by layout, a
+// [time.Layout] such as "app-2006-01-02.log", and hands off to the next
+// file as the date changes. The files need not be rotated in any way:
+// each period simply writes to a file of its own.
+//
+// The current file is the one with the latest date that has begun, in
+// local time; files dated in the future are left alone, so one created
+// shortly before midnight is only followed once its day has started.
+// Whenever the current file has been read to EOF, dir is checked again;
+// if a later file has become current, the tailer switches to it, reads it
+// from the start and emits an [EventSwitched] event. A day without a file
+// is skipped: the next file found, whenever it appears, is followed. Any
+// part of layout finer than a day, such as an hour, works the same way.
+//
+// It fails with [ErrNoMatch] if no file of the series has begun yet.
+// Options apply as for [Follow]; [WithFromStart] affects only the first
+// file.
+func FollowDaily(ctx context.Context, dir, layout string, opts ...Option) (*Tailer, error) {
+	o := defaults()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	path := currentDaily(dir, layout, time.Now())
+	if path == "" {
+		return nil, fmt.Errorf("tailf: %w: %s", ErrNoMatch, filepath.Join(dir, layout))
+	}
+
+	s, err := newTailState(path, o)
+	if err != nil {
+		return nil, fmt.Errorf("tailf: %w", err)
+	}
+	s.next = func(current string) string {
+		path := currentDaily(dir, layout, time.Now())
+		if path == "" || !dailyAfter(path, current, layout) {
+			return current
+		}
+		return path
+	}
+	return s.start(ctx), nil
+}
+
+// currentDaily returns the regular file in dir whose name, parsed with
+// layout, has the latest date not after now, or "" if there is none.
+func currentDaily(dir, layout string, now time.Time) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+
+	var best string
+	var bestDate time.Time
+	for _, e := range entries {
+		date, err := time.ParseInLocation(layout, e.Name(), time.Local)
+		if err != nil || date.After(now) || !e.Type().IsRegular() {
+			continue
+		}
+		if best == "" || date.After(bestDate) {
+			best, bestDate = filepath.Join(dir, e.Name()), date
+		}
+	}
+	return best
+}
+
+// dailyAfter reports whether the file path is dated later than current.
+func dailyAfter(path, current, layout string) bool {
+	date, err := time.ParseInLocation(layout, filepath.Base(path), time.Local)
+	if err != nil {
+		return false
+	}
+	cur, err := time.ParseInLocation(layout, filepath.Base(current), time.Local)
+	return err != nil || date.After(cur)
+}
@@ -0,0 +1,75 @@
+package tailf
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFollowDaily(t *testing.T) {
+	tmp := t.TempDir()
+	const layout = "app-2006-01-02.log"
+	day := func(offset int) string {
+		return filepath.Join(tmp, time.Now().AddDate(0, 0, offset).Format(layout))
+	}
+
+	// Yesterday has no file; today's does not exist yet.
+	if err := os.WriteFile(day(-2), []byte("two days ago\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(day(1), []byte("tomorrow\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events := make(chan Event, 4)
+	tailer, err := FollowDaily(ctx, tmp, layout,
+		WithFromStart(true),
+		WithPollInterval(10*time.Millisecond),
+		WithEventHandler(func(e Event) { events <- e }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := nextLine(ctx, t, tailer); got != "two days ago" {
+		t.Fatalf("got %q, want %q", got, "two days ago")
+	}
+	<-tailer.CaughtUp()
+
+	// Tomorrow's file has not begun and is not switched to.
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case line := <-tailer.Lines():
+		t.Fatalf("got %q from a file dated in the future", line.Text)
+	default:
+	}
+
+	// Today's file appears and takes over across the gap.
+	if err := os.WriteFile(day(0), []byte("today\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := nextLine(ctx, t, tailer); got != "today" {
+		t.Errorf("got %q, want %q", got, "today")
+	}
+	select {
+	case e := <-events:
+		if e.Type != EventSwitched || e.OldPath != day(-2) || e.Path != day(0) {
+			t.Errorf("got %+v, want a switch from %s to %s", e, day(-2), day(0))
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the switch event")
+	}
+}
+
+func TestFollowDailyNoFile(t *testing.T) {
+	tmp := t.TempDir()
+	_, err := FollowDaily(context.Background(), tmp, "app-2006-01-02.log")
+	if !errors.Is(err, ErrNoMatch) {
+		t.Errorf("got %v, want ErrNoMatch", err)
+	}
+}
@@ -0,0 +1,14 @@
+package tailf
+
+import "time"
+
+// armDeadline sets the [WithReadDeadline] deadline for the next read,
+// unless the current file has already refused one.
+func (s *tailState) armDeadline() {
+	if s.noDeadline {
+		return
+	}
+	if err := s.file.SetReadDeadline(time.Now().Add(s.o.readDeadline)); err != nil {
+		s.noDeadline = true
+	}
+}
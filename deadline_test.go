@@ -0,0 +1,66 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package tailf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReadDeadlineInterruptsHungRead(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "fifo")
+	if err := syscall.Mkfifo(path, 0644); err != nil {
+		t.Skipf("mkfifo: %v", err)
+	}
+
+	// Opening either end of a FIFO blocks until the other is opened.
+	writer := make(chan *os.File)
+	go func() {
+		w, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			close(writer)
+			return
+		}
+		writer <- w
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFromStart(true), WithReadDeadline(20*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, ok := <-writer
+	if !ok {
+		t.Fatal("could not open the FIFO for writing")
+	}
+	defer w.Close()
+
+	w.WriteString("hello\n")
+	select {
+	case line := <-tailer.Lines():
+		if line.Text != "hello" {
+			t.Errorf("got %q, want %q", line.Text, "hello")
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for line")
+	}
+
+	// The writer stays open, so the next read hangs until its deadline.
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case <-tailer.Done():
+	case <-time.After(time.Second):
+		t.Fatal("tailer did not stop while a read was hanging")
+	}
+	if err := tailer.Err(); err != nil {
+		t.Errorf("Err = %v, want nil", err)
+	}
+}
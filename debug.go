@@ -0,0 +1,45 @@
+package tailf
+
+// DebugState is a snapshot of the tailer's reading state, returned by
+// [Tailer.DebugState] for diagnosing torn or delayed lines. It is meant
+// for bug reports and tests only: its fields may change in any release.
+type DebugState struct {
+	// BufferedBytes is the number of bytes read from the file into the
+	// reader's buffer but not yet framed.
+	BufferedBytes int
+
+	// PartialBytes is the length of the incomplete record at the current
+	// position, as seen by the last read that reached the end of the
+	// file. It is read again once the rest of it arrives.
+	PartialBytes int
+
+	// Offset is the position in the file of the next byte to be framed,
+	// or -1 if it cannot be determined.
+	Offset int64
+
+	// AtEOF is set while the last read reached the end of the file.
+	AtEOF bool
+
+	// CurrentPath is the path of the file being read.
+	CurrentPath string
+}
+
+// DebugState returns a snapshot of the tailer's reading state, taken on
+// the tailing goroutine between two reads. It is diagnostic only; see
+// [DebugState]. It returns the zero DebugState if the tailer is no
+// longer running or, under [WithFollowByName], has not opened its file
+// yet.
+func (t *Tailer) DebugState() DebugState {
+	var d DebugState
+	t.do(func(s *tailState) error {
+		d = DebugState{
+			BufferedBytes: s.scan.r.Buffered(),
+			PartialBytes:  s.pending,
+			Offset:        s.readOffset(),
+			AtEOF:         s.atEOF,
+			CurrentPath:   s.path,
+		}
+		return nil
+	})
+	return d
+}
@@ -0,0 +1,39 @@
+package tailf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDebugState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, []byte("one\ntw"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFromStart(true), WithPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := nextLine(ctx, t, tailer); got != "one" {
+		t.Fatalf("got %q, want %q", got, "one")
+	}
+	<-tailer.CaughtUp()
+
+	want := DebugState{PartialBytes: 2, Offset: 4, AtEOF: true, CurrentPath: path}
+	if got := tailer.DebugState(); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	cancel()
+	<-tailer.Done()
+	if got := tailer.DebugState(); got != (DebugState{}) {
+		t.Errorf("got %+v after stopping, want the zero DebugState", got)
+	}
+}
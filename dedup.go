@@ -0,0 +1,53 @@
+package tailf
+
+import "hash/fnv"
+
+// hashRing remembers the hashes of the last lines delivered, for
+// [WithDedupRecent].
+type hashRing struct {
+	buf   []uint64
+	start int
+	n     int
+	seen  map[uint64]struct{}
+}
+
+func newHashRing(size int) *hashRing {
+	if size <= 0 {
+		return nil
+	}
+	return &hashRing{buf: make([]uint64, size), seen: make(map[uint64]struct{}, size)}
+}
+
+// add records h, forgetting the oldest hash if the ring is full, and
+// reports whether h was already in the ring, in which case it is left
+// in place.
+func (r *hashRing) add(h uint64) bool {
+	if _, ok := r.seen[h]; ok {
+		return true
+	}
+	if r.n < len(r.buf) {
+		r.buf[(r.start+r.n)%len(r.buf)] = h
+		r.n++
+	} else {
+		delete(r.seen, r.buf[r.start])
+		r.buf[r.start] = h
+		r.start = (r.start + 1) % len(r.buf)
+	}
+	r.seen[h] = struct{}{}
+	return false
+}
+
+// duplicate reports whether l has the same text as one of the last
+// lines seen under [WithDedupRecent], and must be skipped.
+func (s *tailState) duplicate(l Line) bool {
+	if s.recent == nil {
+		return false
+	}
+	h := fnv.New64a()
+	h.Write([]byte(l.Text))
+	if !s.recent.add(h.Sum64()) {
+		return false
+	}
+	s.t.updateStats(func(st *Stats) { st.Duplicates++ })
+	return true
+}
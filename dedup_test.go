@@ -0,0 +1,88 @@
+package tailf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDedupRecentWindow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	content := strings.Join([]string{"a", "b", "a", "c", "d", "a"}, "\n") + "\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFromStart(true), WithStopAtEOF(true), WithDedupRecent(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for line := range tailer.Lines() {
+		got = append(got, line.Text)
+	}
+
+	// The second "a" is within the last three lines and skipped; by the
+	// third, "a" has left the window.
+	want := "a b c d a"
+	if strings.Join(got, " ") != want {
+		t.Errorf("got %q, want %q", strings.Join(got, " "), want)
+	}
+	if n := tailer.Stats().Duplicates; n != 1 {
+		t.Errorf("got %d duplicates, want 1", n)
+	}
+}
+
+func TestDedupRecentAcrossTruncation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, []byte("1\n2\n3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path,
+		WithFromStart(true),
+		WithPollInterval(10*time.Millisecond),
+		WithDedupRecent(10),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		<-tailer.Lines()
+	}
+	<-tailer.CaughtUp()
+
+	// Rewrite the file with the same lines and one more, shorter than
+	// the position so that it is read again from the start.
+	if err := os.WriteFile(path, []byte("1\n2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("3\n4\n")
+	f.Close()
+
+	select {
+	case line := <-tailer.Lines():
+		if line.Text != "4" {
+			t.Errorf("got %q, want %q", line.Text, "4")
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the new line")
+	}
+	if n := tailer.Stats().Duplicates; n != 3 {
+		t.Errorf("got %d duplicates, want 3", n)
+	}
+}
@@ -0,0 +1,65 @@
+package tailf
+
+import (
+	"fmt"
+	"io"
+)
+
+// TailError describes a fatal error that stopped a tailer, or that a
+// [Tailer] method could not recover from. Use [errors.As] to extract it
+// from [Tailer.Err], for example to record the offset of the failure.
+type TailError struct {
+	// Op is the operation that failed, such as "read", "seek" or "stat".
+	Op string
+
+	// Path is the path being followed when the error occurred.
+	Path string
+
+	// Offset is the read position in the file when the error occurred,
+	// or -1 if it could not be determined. If reading the file failed
+	// midway through a record, it is the offset of the start of that
+	// record, from which reading can be resumed. A record rejected by a
+	// [Framer] lies before it.
+	Offset int64
+
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *TailError) Error() string {
+	return fmt.Sprintf("tailf: %s %s at offset %d: %v", e.Op, e.Path, e.Offset, e.Err)
+}
+
+func (e *TailError) Unwrap() error {
+	return e.Err
+}
+
+// fail wraps err, which occurred during op, in a *TailError carrying the
+// current path and the start of the record being read.
+func (s *tailState) fail(op string, err error) error {
+	offset := s.offset()
+	if offset >= 0 && s.gz == nil {
+		// Bytes of the record already consumed are not counted.
+		offset -= int64(s.scan.pending)
+	}
+	return &TailError{
+		Op:     op,
+		Path:   s.path,
+		Offset: offset,
+		Err:    err,
+	}
+}
+
+// offset returns the position in the file of the next byte the framer
+// will read, or -1 if it cannot be determined. For compressed input it
+// is the end of the last member decoded.
+func (s *tailState) offset() int64 {
+	if s.gz != nil {
+		return s.gz.off
+	}
+	pos, err := s.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return -1
+	}
+	return pos - int64(s.scan.r.Buffered())
+}
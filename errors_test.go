@@ -0,0 +1,143 @@
+package tailf
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var errCorrupt = errors.New("corrupt record")
+
+// failingFramer frames lines until it meets one starting with "!".
+type failingFramer struct{}
+
+func (failingFramer) Frame(r *bufio.Reader) (int, []byte, error) {
+	n, payload, err := lineFramer{}.Frame(r)
+	if err == nil && strings.HasPrefix(string(payload), "!") {
+		return n, nil, errCorrupt
+	}
+	return n, payload, err
+}
+
+func TestTailErrorFromReadFailure(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("good\n!bad\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFromStart(true), WithFramer(failingFramer{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range tailer.Lines() {
+	}
+
+	err = tailer.Err()
+	var te *TailError
+	if !errors.As(err, &te) {
+		t.Fatalf("got %v, want a *TailError", err)
+	}
+	if te.Op != "read" || te.Path != path || !errors.Is(err, errCorrupt) {
+		t.Errorf("got %+v, want read error on %s wrapping errCorrupt", te, path)
+	}
+	// The failing record was fully consumed by the framer.
+	if te.Offset != int64(len("good\n!bad\n")) {
+		t.Errorf("Offset = %d, want %d", te.Offset, len("good\n!bad\n"))
+	}
+	if !strings.HasPrefix(err.Error(), "tailf:") {
+		t.Errorf("error should be prefixed with 'tailf:', got: %v", err)
+	}
+}
+
+// brokenFramer fails to read the second half of the line starting with
+// "!", as a failing disk would.
+type brokenFramer struct{}
+
+func (brokenFramer) Frame(r *bufio.Reader) (int, []byte, error) {
+	if b, err := r.Peek(1); err == nil && b[0] == '!' {
+		n, _ := r.Discard(2)
+		return n, nil, &os.PathError{Op: "read", Path: "test.log", Err: errCorrupt}
+	}
+	return lineFramer{}.Frame(r)
+}
+
+func TestTailErrorFromFailureMidRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, []byte("good\n!bad\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFromStart(true), WithFramer(brokenFramer{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range tailer.Lines() {
+	}
+
+	// The offset is the start of the record being read, not the middle
+	// of it where reading failed.
+	var te *TailError
+	if !errors.As(tailer.Err(), &te) {
+		t.Fatalf("got %v, want a *TailError", tailer.Err())
+	}
+	if te.Offset != int64(len("good\n")) {
+		t.Errorf("Offset = %d, want %d", te.Offset, len("good\n"))
+	}
+}
+
+func TestFollowMaxFileSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, []byte("line 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path,
+		WithFromStart(true),
+		WithPollInterval(10*time.Millisecond),
+		WithMaxFileSize(20),
+		WithSupervise(time.Millisecond, 3),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-tailer.CaughtUp()
+
+	// Lines up to the limit and beyond it are delivered before the
+	// tailer notices the size at the end of the file.
+	appendLine(t, path, "line 2")
+	appendLine(t, path, "line 3")
+	var got []string
+	for line := range tailer.Lines() {
+		got = append(got, line.Text)
+	}
+	if strings.Join(got, ",") != "line 1,line 2,line 3" {
+		t.Errorf("got %q", got)
+	}
+
+	var te *TailError
+	if err := tailer.Err(); !errors.Is(err, ErrFileTooLarge) || !errors.As(err, &te) {
+		t.Fatalf("got %v, want a TailError wrapping ErrFileTooLarge", err)
+	}
+	if te.Path != path || te.Offset != 21 {
+		t.Errorf("got path %q, offset %d, want %q, 21", te.Path, te.Offset, path)
+	}
+	if n := tailer.Stats().Reopens; n != 0 {
+		t.Errorf("got %d restarts, want none", n)
+	}
+}
@@ -0,0 +1,67 @@
+package tailf
+
+import "time"
+
+// EventType identifies the kind of an [Event].
+type EventType int
+
+const (
+	// EventTruncated is emitted when the file was truncated in place and
+	// the tailer rewound to the start.
+	EventTruncated EventType = iota + 1
+
+	// EventRotated is emitted when the file at the path was replaced and
+	// the tailer switched to the new file.
+	EventRotated
+
+	// EventReopened is emitted when the tailer reopened the path to
+	// recover from a handle that stopped making progress, or re-read the
+	// file from the start on a [WithReplaySignal] signal.
+	EventReopened
+
+	// EventSwitched is emitted when the tailer moved on to a different
+	// path, such as a newer file picked by [FollowLatest].
+	EventSwitched
+)
+
+// String returns the name of the event type.
+func (e EventType) String() string {
+	switch e {
+	case EventTruncated:
+		return "truncated"
+	case EventRotated:
+		return "rotated"
+	case EventReopened:
+		return "reopened"
+	case EventSwitched:
+		return "switched"
+	default:
+		return "unknown"
+	}
+}
+
+// Event describes a change in the state of the tailed file.
+type Event struct {
+	// Type is the kind of change.
+	Type EventType
+
+	// Path is the path being tailed.
+	Path string
+
+	// OldPath is the previously tailed path for [EventSwitched].
+	OldPath string
+
+	// Time is when the change was detected.
+	Time time.Time
+
+	// Position is the token for the tailer's position right after the
+	// change, as [Tailer.Position] would return it. For a rotation,
+	// truncation or switch it is the start of the new file, in the new
+	// generation, so a checkpoint written with it at the boundary resumes
+	// with the new file, neither reading the old one again nor skipping
+	// the start of the new one. Lines of the old file may still be
+	// buffered in the [Tailer.Lines] channel when the event is emitted
+	// ([Tailer.Pending] of them); store the token once they have been
+	// handled. It is the zero Token under [WithGzipInput].
+	Position Token
+}
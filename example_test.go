@@ -55,3 +55,21 @@ func ExampleFollowFunc() {
 	// two
 	// three
 }
+
+func ExampleEach() {
+	// Create a temp file for the example.
+	dir, _ := os.MkdirTemp("", "tailf-example")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.log")
+	os.WriteFile(path, []byte("starting\nready\nserving\n"), 0644)
+
+	// Print lines until the service reports it is ready.
+	tailf.Each(path, func(line tailf.Line) bool {
+		fmt.Println(line.Text)
+		return line.Text != "ready"
+	}, tailf.WithFromStart(true))
+
+	// Output:
+	// starting
+	// ready
+}
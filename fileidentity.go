@@ -2,23 +2,39 @@
 
 package tailf
 
-import (
-	"os"
-	"syscall"
-)
+import "strconv"
+
+// RotationDetectionSupported reports whether this platform provides the
+// file identities that rotation detection relies on. Where it does not,
+// a tailer still handles truncation but does not notice a file being
+// replaced at its path, so callers may want to warn, or to use
+// [WithRestartOnShrink] or their own name-based strategy instead.
+func RotationDetectionSupported() bool {
+	return true
+}
 
 type fileIdentity struct {
 	dev uint64
 	ino uint64
 }
 
-func getFileIdentity(info os.FileInfo) fileIdentity {
-	stat, ok := info.Sys().(*syscall.Stat_t)
-	if !ok {
-		return fileIdentity{}
+// replacedBy reports whether other, the identity now found at the path,
+// belongs to a different file than id. Only the inode decides: with bind
+// mounts, overlay filesystems and the like, the path and an open handle
+// to the same file can report different devices, which must not look
+// like a rotation. An unknown identity never counts as a replacement.
+func (id fileIdentity) replacedBy(other fileIdentity) bool {
+	if id == (fileIdentity{}) || other == (fileIdentity{}) {
+		return false
 	}
-	return fileIdentity{
-		dev: uint64(stat.Dev),
-		ino: uint64(stat.Ino),
+	return id.ino != other.ino
+}
+
+// token returns the identity as recorded in a [Token], or "" if it is
+// unknown.
+func (id fileIdentity) token() string {
+	if id == (fileIdentity{}) {
+		return ""
 	}
+	return "ino:" + strconv.FormatUint(id.ino, 10)
 }
@@ -0,0 +1,53 @@
+//go:build !windows
+
+package tailf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileIdentityReplacedBy(t *testing.T) {
+	tests := []struct {
+		old, new fileIdentity
+		want     bool
+	}{
+		{fileIdentity{dev: 1, ino: 5}, fileIdentity{dev: 1, ino: 5}, false},
+		{fileIdentity{dev: 1, ino: 5}, fileIdentity{dev: 1, ino: 6}, true},
+		{fileIdentity{dev: 1, ino: 5}, fileIdentity{dev: 2, ino: 5}, false}, // bind mount
+		{fileIdentity{dev: 1, ino: 5}, fileIdentity{dev: 2, ino: 6}, true},
+		{fileIdentity{dev: 1, ino: 5}, fileIdentity{}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.old.replacedBy(tt.new); got != tt.want {
+			t.Errorf("%+v.replacedBy(%+v) = %v, want %v", tt.old, tt.new, got, tt.want)
+		}
+	}
+}
+
+func TestCheckFileStateIgnoresDeviceMismatch(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("data\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := newTailState(path, defaults())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.close()
+
+	// Simulate a bind mount, where the handle reports another device
+	// than a stat of the path.
+	s.fileID.dev++
+
+	if reopened, err := s.checkFileState(); err != nil || reopened {
+		t.Fatalf("checkFileState = %v, %v; want no rotation", reopened, err)
+	}
+	if got := s.t.Stats().Rotations; got != 0 {
+		t.Errorf("Rotations = %d, want 0", got)
+	}
+}
@@ -0,0 +1,20 @@
+package tailf
+
+import (
+	"os"
+	"syscall"
+)
+
+// getFileIdentity on Plan 9 uses the file server's unique path of the
+// file's qid as its inode, and the server type and subtype as its
+// device.
+func getFileIdentity(info os.FileInfo) fileIdentity {
+	dir, ok := info.Sys().(*syscall.Dir)
+	if !ok {
+		return fileIdentity{}
+	}
+	return fileIdentity{
+		dev: uint64(dir.Type)<<32 | uint64(dir.Dev),
+		ino: dir.Qid.Path,
+	}
+}
@@ -0,0 +1,25 @@
+//go:build !windows && !plan9
+
+package tailf
+
+import (
+	"os"
+	"syscall"
+)
+
+// getFileIdentity returns the device and inode of info. Ino is a uint64
+// on every platform, 32-bit ones included, but Dev varies: a uint32 on
+// linux/mips and dragonfly, an int32 on darwin and openbsd, an int64 on
+// js. Widening a uint32 is exact and a negative Dev always widens to the
+// same value, so identities still compare correctly; only the inode
+// decides a replacement in any case.
+func getFileIdentity(info os.FileInfo) fileIdentity {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileIdentity{}
+	}
+	return fileIdentity{
+		dev: uint64(stat.Dev),
+		ino: uint64(stat.Ino),
+	}
+}
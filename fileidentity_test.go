@@ -0,0 +1,74 @@
+package tailf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileIdentity(t *testing.T) {
+	if !RotationDetectionSupported() {
+		t.Skip("no file identities on this platform")
+	}
+	tmp := t.TempDir()
+	a := filepath.Join(tmp, "a.log")
+	b := filepath.Join(tmp, "b.log")
+	for _, path := range []string{a, b} {
+		if err := os.WriteFile(path, []byte("line\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	identity := func(path string) fileIdentity {
+		t.Helper()
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id := getFileIdentity(info)
+		if id == (fileIdentity{}) {
+			t.Fatalf("%s: empty identity", path)
+		}
+		return id
+	}
+
+	idA, idB := identity(a), identity(b)
+	if !idA.replacedBy(idB) {
+		t.Errorf("different files: got %+v and %+v", idA, idB)
+	}
+
+	// The same file keeps its identity through an open handle, writes
+	// and a rename. Identities are compared with replacedBy, since on
+	// Windows two stats of the same file give different values.
+	f, err := os.OpenFile(a, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := getFileIdentity(info); idA.replacedBy(id) {
+		t.Errorf("open handle: got %+v, want %+v", id, idA)
+	}
+	if _, err := f.WriteString("more\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if id := identity(a); idA.replacedBy(id) {
+		t.Errorf("after a write: got %+v, want %+v", id, idA)
+	}
+	moved := filepath.Join(tmp, "a.log.1")
+	if err := os.Rename(a, moved); err != nil {
+		t.Fatal(err)
+	}
+	if id := identity(moved); idA.replacedBy(id) {
+		t.Errorf("after a rename: got %+v, want %+v", id, idA)
+	}
+
+	// An unknown identity is never a replacement.
+	if idA.replacedBy(fileIdentity{}) || (fileIdentity{}).replacedBy(idA) {
+		t.Error("unknown identity counted as a replacement")
+	}
+}
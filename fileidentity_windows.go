@@ -4,14 +4,36 @@ package tailf
 
 import "os"
 
+// RotationDetectionSupported reports whether this platform provides the
+// file identities that rotation detection relies on. Windows provides
+// them as a volume serial number and file index.
+func RotationDetectionSupported() bool {
+	return true
+}
+
+// fileIdentity on Windows keeps the stat itself: the volume serial number
+// and file index that identify a file are only reachable through
+// [os.SameFile], which compares them.
 type fileIdentity struct {
-	dev uint64
-	ino uint64
+	info os.FileInfo
+}
+
+func getFileIdentity(info os.FileInfo) fileIdentity {
+	return fileIdentity{info: info}
+}
+
+// replacedBy reports whether other, the identity now found at the path,
+// belongs to a different file than id. An unknown identity never counts
+// as a replacement.
+func (id fileIdentity) replacedBy(other fileIdentity) bool {
+	if id.info == nil || other.info == nil {
+		return false
+	}
+	return !os.SameFile(id.info, other.info)
 }
 
-// getFileIdentity on Windows returns an empty identity.
-// Rotation detection based on inode is not available on Windows,
-// so the tailer degrades gracefully to truncation detection only.
-func getFileIdentity(_ os.FileInfo) fileIdentity {
-	return fileIdentity{}
+// token returns the identity as recorded in a [Token]. The file index is
+// not exposed, so tokens carry none and resuming cannot tell files apart.
+func (id fileIdentity) token() string {
+	return ""
 }
@@ -0,0 +1,106 @@
+package tailf
+
+import "strings"
+
+// lineRing is a fixed-capacity FIFO of lines that overwrites its oldest
+// entry when full. A zero-capacity ring discards everything.
+type lineRing struct {
+	buf   []Line
+	start int
+	n     int
+	bytes int // total length of the texts held
+}
+
+func newLineRing(size int) lineRing {
+	if size <= 0 {
+		return lineRing{}
+	}
+	return lineRing{buf: make([]Line, size)}
+}
+
+func (r *lineRing) push(l Line) {
+	if len(r.buf) == 0 {
+		return
+	}
+	r.bytes += len(l.Text)
+	if r.n < len(r.buf) {
+		r.buf[(r.start+r.n)%len(r.buf)] = l
+		r.n++
+		return
+	}
+	r.bytes -= len(r.buf[r.start].Text)
+	r.buf[r.start] = l
+	r.start = (r.start + 1) % len(r.buf)
+}
+
+func (r *lineRing) len() int { return r.n }
+
+// at returns the i-th oldest line in the ring.
+func (r *lineRing) at(i int) Line {
+	return r.buf[(r.start+i)%len(r.buf)]
+}
+
+func (r *lineRing) reset() {
+	clear(r.buf)
+	r.start, r.n, r.bytes = 0, 0, 0
+}
+
+// prefixSet matches lines against a list of prefixes, indexed by their
+// first byte so that most lines are ruled out by a single lookup.
+type prefixSet struct {
+	byFirst [256][]string
+	any     bool // the empty prefix, which every line has
+}
+
+func newPrefixSet(prefixes []string) *prefixSet {
+	if len(prefixes) == 0 {
+		return nil
+	}
+	p := &prefixSet{}
+	for _, prefix := range prefixes {
+		if prefix == "" {
+			p.any = true
+			continue
+		}
+		p.byFirst[prefix[0]] = append(p.byFirst[prefix[0]], prefix)
+	}
+	return p
+}
+
+// match reports whether text starts with one of the prefixes.
+func (p *prefixSet) match(text string) bool {
+	if p.any {
+		return true
+	}
+	if text == "" {
+		return false
+	}
+	for _, prefix := range p.byFirst[text[0]] {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// filtering reports whether any of [WithPrefixAllow], [WithPrefixDeny],
+// [WithFilterChain] and [WithFilter] is set.
+func (o *options) filtering() bool {
+	return o.filter != nil || o.prefixAllow != nil || o.prefixDeny != nil || o.filterChain != nil
+}
+
+// accepts reports whether l passes the prefix lists, which are checked
+// first, then the [WithFilterChain] rules and then the [WithFilter]
+// predicate.
+func (o *options) accepts(l Line) bool {
+	if o.prefixDeny != nil && o.prefixDeny.match(l.Text) {
+		return false
+	}
+	if o.prefixAllow != nil && !o.prefixAllow.match(l.Text) {
+		return false
+	}
+	if o.filterChain != nil && !o.filterChain.Allows(l.Text) {
+		return false
+	}
+	return o.filter == nil || o.filter(l)
+}
@@ -0,0 +1,213 @@
+package tailf
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFollowFilterWithContextLines(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	content := strings.Join([]string{
+		"x1", "x2", "x3", "ERR a", "x5", "x6", "ERR b",
+		"x8", "x9", "x10", "ERR c", "x12", "x13", "x14",
+	}, "\n") + "\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path,
+		WithFromStart(true),
+		WithFilter(func(l Line) bool { return strings.HasPrefix(l.Text, "ERR") }),
+		WithContextLines(2, 1),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Overlapping windows are merged: x5 is trailing context of "ERR a"
+	// and must not be repeated as leading context of "ERR b".
+	expected := []string{
+		"x2", "x3", "ERR a", "x5",
+		"x6", "ERR b", "x8",
+		"x9", "x10", "ERR c", "x12",
+	}
+
+	var lines []string
+	for range expected {
+		select {
+		case line := <-tailer.Lines():
+			lines = append(lines, line.Text)
+		case <-ctx.Done():
+			t.Fatalf("timed out after receiving %v", lines)
+		}
+	}
+	<-tailer.CaughtUp()
+
+	select {
+	case line := <-tailer.Lines():
+		t.Errorf("unexpected extra line %q", line.Text)
+	default:
+	}
+
+	for i, want := range expected {
+		if lines[i] != want {
+			t.Errorf("line %d: got %q, want %q", i, lines[i], want)
+		}
+	}
+
+	cancel()
+	<-tailer.Done()
+}
+
+func TestLineRing(t *testing.T) {
+	r := newLineRing(2)
+	for _, s := range []string{"a", "b", "c"} {
+		r.push(Line{Text: s})
+	}
+	if r.len() != 2 || r.at(0).Text != "b" || r.at(1).Text != "c" {
+		t.Errorf("ring holds %d lines starting %q, want b, c", r.len(), r.at(0).Text)
+	}
+	r.reset()
+	if r.len() != 0 {
+		t.Errorf("len after reset = %d, want 0", r.len())
+	}
+
+	empty := newLineRing(0)
+	empty.push(Line{Text: "dropped"})
+	if empty.len() != 0 {
+		t.Errorf("zero-size ring len = %d, want 0", empty.len())
+	}
+}
+
+func TestReadAllLineDecorator(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	data := "route=a one\nroute=b two\nroute=a three\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// The decorator sees the transformed text, and the filter sees Meta.
+	decorate := func(l *Line) {
+		key, rest, _ := strings.Cut(strings.TrimPrefix(l.Text, "ROUTE="), " ")
+		l.Meta = map[string]any{"route": key}
+		l.Text = rest
+	}
+	lines, err := ReadAll(ctx, path,
+		WithTransform(strings.ToUpper),
+		WithLineDecorator(decorate),
+		WithFilter(func(l Line) bool { return l.Meta["route"] == "A" }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"ONE", "THREE"}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d", len(lines), len(want))
+	}
+	for i, w := range want {
+		if lines[i].Text != w || lines[i].Meta["route"] != "A" {
+			t.Errorf("line %d: got %q meta %v, want %q route A", i, lines[i].Text, lines[i].Meta, w)
+		}
+	}
+}
+
+func TestReadAllPrefixFilters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	data := "ERROR disk full\nINFO started\nWARN slow\nERROR timeout\nERRORS summary\nDEBUG x\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tests := []struct {
+		name string
+		opts []Option
+		want []string
+	}{
+		{"allow", []Option{WithPrefixAllow("ERROR", "WARN")},
+			[]string{"ERROR disk full", "WARN slow", "ERROR timeout", "ERRORS summary"}},
+		{"deny", []Option{WithPrefixDeny("DEBUG", "INFO")},
+			[]string{"ERROR disk full", "WARN slow", "ERROR timeout", "ERRORS summary"}},
+		{"deny wins", []Option{WithPrefixAllow("ERROR"), WithPrefixDeny("ERRORS")},
+			[]string{"ERROR disk full", "ERROR timeout"}},
+		{"then filter", []Option{
+			WithPrefixAllow("ERROR", "INFO"),
+			WithFilter(func(l Line) bool { return !strings.HasPrefix(l.Text, "INFO") }),
+		}, []string{"ERROR disk full", "ERROR timeout", "ERRORS summary"}},
+		{"empty prefix", []Option{WithPrefixAllow("")},
+			[]string{"ERROR disk full", "INFO started", "WARN slow", "ERROR timeout", "ERRORS summary", "DEBUG x"}},
+	}
+	for _, tt := range tests {
+		lines, err := ReadAll(ctx, path, tt.opts...)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var got []string
+		for _, l := range lines {
+			got = append(got, l.Text)
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func BenchmarkPrefixFilter(b *testing.B) {
+	// Raise to 1_000_000 to compare on a file of a realistic size.
+	const benchFilterLines = 100_000
+
+	path := filepath.Join(b.TempDir(), "bench.log")
+	f, err := os.Create(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	w := bufio.NewWriter(f)
+	levels := []string{"DEBUG", "INFO", "INFO", "INFO", "WARN", "ERROR"}
+	var matching int
+	for i := range benchFilterLines {
+		if i%len(levels) >= 4 {
+			matching++
+		}
+		fmt.Fprintf(w, "%s 2024-01-01T00:00:00Z request %d served in 12ms\n", levels[i%len(levels)], i)
+	}
+	w.Flush()
+	f.Close()
+
+	re := regexp.MustCompile(`^(?:WARN|ERROR)`)
+	filters := map[string]Option{
+		"prefix": WithPrefixAllow("WARN", "ERROR"),
+		"regexp": WithFilter(func(l Line) bool { return re.MatchString(l.Text) }),
+	}
+	for _, name := range []string{"prefix", "regexp"} {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				lines, err := ReadAll(context.Background(), path, filters[name])
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(lines) != matching {
+					b.Fatalf("got %d lines, want %d", len(lines), matching)
+				}
+			}
+		})
+	}
+}
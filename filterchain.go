@@ -0,0 +1,67 @@
+package tailf
+
+import (
+	"regexp"
+	"strings"
+)
+
+// FilterRule is one rule of a [FilterChain]. It matches a line by a
+// prefix or a regular expression and either allows or denies it. Rules
+// are made with [Allow], [Deny], [AllowPrefix] and [DenyPrefix].
+type FilterRule struct {
+	deny   bool
+	prefix string
+	re     *regexp.Regexp
+}
+
+// Allow returns a rule that delivers the lines re matches.
+func Allow(re *regexp.Regexp) FilterRule { return FilterRule{re: re} }
+
+// Deny returns a rule that drops the lines re matches.
+func Deny(re *regexp.Regexp) FilterRule { return FilterRule{deny: true, re: re} }
+
+// AllowPrefix returns a rule that delivers the lines starting with prefix.
+func AllowPrefix(prefix string) FilterRule { return FilterRule{prefix: prefix} }
+
+// DenyPrefix returns a rule that drops the lines starting with prefix.
+func DenyPrefix(prefix string) FilterRule { return FilterRule{deny: true, prefix: prefix} }
+
+func (r FilterRule) match(text string) bool {
+	if r.re != nil {
+		return r.re.MatchString(text)
+	}
+	return strings.HasPrefix(text, r.prefix)
+}
+
+// FilterChain decides which lines are delivered by an ordered list of
+// rules, for [WithFilterChain]. The rules are tried in order and the
+// first that matches a line decides it; later rules are not evaluated.
+// A line that no rule matches is delivered, unless DefaultDeny is set.
+//
+// To keep the lines matching A unless they also match B, deny B before
+// allowing A, and set DefaultDeny:
+//
+//	FilterChain{
+//		Rules:       []FilterRule{Deny(b), Allow(a)},
+//		DefaultDeny: true,
+//	}
+//
+// A prefix rule costs a string comparison, so rules placed ahead of the
+// regular expressions decide most lines without running one.
+type FilterChain struct {
+	Rules []FilterRule
+
+	// DefaultDeny drops the lines that no rule matches. By default they
+	// are delivered.
+	DefaultDeny bool
+}
+
+// Allows reports whether the chain delivers a line with the given text.
+func (c *FilterChain) Allows(text string) bool {
+	for _, r := range c.Rules {
+		if r.match(text) {
+			return !r.deny
+		}
+	}
+	return !c.DefaultDeny
+}
@@ -0,0 +1,97 @@
+package tailf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestReadAllFilterChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	data := "ERROR payment declined\nERROR cache miss\nWARN slow query\nINFO started\nwarn: retrying\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tests := []struct {
+		name  string
+		chain FilterChain
+		want  []string
+	}{
+		// The allow rule comes first, so it overrides the deny rule for
+		// the lines both match.
+		{"allow then deny", FilterChain{Rules: []FilterRule{
+			AllowPrefix("ERROR payment"),
+			DenyPrefix("ERROR"),
+		}}, []string{"ERROR payment declined", "WARN slow query", "INFO started", "warn: retrying"}},
+		{"deny then allow", FilterChain{Rules: []FilterRule{
+			DenyPrefix("ERROR"),
+			AllowPrefix("ERROR payment"),
+		}}, []string{"WARN slow query", "INFO started", "warn: retrying"}},
+		{"default deny", FilterChain{Rules: []FilterRule{
+			Deny(regexp.MustCompile(`slow`)),
+			Allow(regexp.MustCompile(`(?i)^warn`)),
+			AllowPrefix("ERROR payment"),
+		}, DefaultDeny: true}, []string{"ERROR payment declined", "warn: retrying"}},
+		{"empty", FilterChain{}, SplitLines([]byte(data))},
+	}
+	for _, tt := range tests {
+		lines, err := ReadAll(ctx, path, WithFilterChain(tt.chain))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var got []string
+		for _, l := range lines {
+			got = append(got, l.Text)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestConfigFilterChain(t *testing.T) {
+	cfg := Config{FilterChain: &Filters{
+		Rules: []FilterSpec{
+			{Action: "allow", Prefix: "ERROR payment"},
+			{Action: "deny", Regexp: `^(ERROR|DEBUG)`},
+		},
+		DefaultDeny: true,
+	}}
+	opts, err := cfg.Options()
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := defaults()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	for text, want := range map[string]bool{
+		"ERROR payment declined": true,
+		"ERROR cache miss":       false,
+		"INFO started":           false,
+	} {
+		if got := o.accepts(Line{Text: text}); got != want {
+			t.Errorf("%q: got %v, want %v", text, got, want)
+		}
+	}
+
+	for _, spec := range []FilterSpec{
+		{Action: "keep", Prefix: "ERROR"},
+		{Action: "deny"},
+		{Action: "deny", Prefix: "ERROR", Regexp: "ERROR"},
+		{Action: "allow", Regexp: "("},
+	} {
+		cfg := Config{FilterChain: &Filters{Rules: []FilterSpec{spec}}}
+		if _, err := cfg.Options(); err == nil {
+			t.Errorf("%+v: expected an error", spec)
+		}
+	}
+}
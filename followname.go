@@ -0,0 +1,57 @@
+package tailf
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// errNotOpened is reported by commands sent to a [WithFollowByName]
+// tailer that is still waiting for its file.
+var errNotOpened = errors.New("file not opened yet")
+
+// waitForFile waits for the path of a [WithFollowByName] tailer that
+// could not be opened at the start to become openable, retrying every
+// poll interval and on notifications. The file is read from the start,
+// since everything in it was written after tailing began. Commands are
+// answered with an error in the meantime. It returns false if ctx was
+// cancelled first.
+func (s *tailState) waitForFile(ctx context.Context) bool {
+	o := s.o
+	o.fromStart = true
+	o.startPercent, o.initialBytes = -1, 0
+
+	timer := time.NewTimer(s.waitInterval())
+	defer timer.Stop()
+	for {
+		for _, path := range append([]string{s.primary}, s.o.fallbackPaths...) {
+			file, reader, info, _, err := openFile(path, o)
+			if err != nil {
+				continue
+			}
+			s.file = file
+			s.path = path
+			s.identify(info)
+			s.indexPath()
+			s.scan.r = reader
+			s.expectHeader()
+			s.t.setInitialSize(file)
+			if s.o.gzip {
+				s.scan.r = s.newReader(file)
+			}
+			s.markStart()
+			s.announce(LifecycleEvent{Type: LifecycleOpened})
+			return true
+		}
+
+		timer.Reset(s.waitInterval())
+		select {
+		case <-timer.C:
+		case <-s.o.notify:
+		case c := <-s.t.cmds:
+			c.done <- s.fail("open", errNotOpened)
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package tailf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFollowByName(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path,
+		WithFollowByName(true),
+		WithPollInterval(10*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("Follow on a missing path = %v, want nil", err)
+	}
+	if err := tailer.Pause(); err == nil {
+		t.Error("Pause before the file exists succeeded")
+	}
+
+	readLine := func(want string) {
+		t.Helper()
+		select {
+		case line := <-tailer.Lines():
+			if line.Text != want {
+				t.Errorf("got %q, want %q", line.Text, want)
+			}
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for %q", want)
+		}
+	}
+
+	// The file is created after tailing began and read from the start.
+	if err := os.WriteFile(path, []byte("created\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	readLine("created")
+
+	// Deleted and recreated under the same name.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(path, []byte("recreated\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	readLine("recreated")
+
+	cancel()
+	<-tailer.Done()
+	if err := tailer.Err(); err != nil {
+		t.Errorf("Err = %v", err)
+	}
+}
+
+func TestOpenPullFollowByNameMissing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.log")
+	if _, err := OpenPull(context.Background(), path, WithFollowByName(true)); err == nil {
+		t.Error("OpenPull on a missing path succeeded")
+	}
+}
@@ -0,0 +1,171 @@
+package tailf
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Framer splits the byte stream of a tailed file into records. The
+// default framer splits on newlines; see [WithFramer] to replace it.
+type Framer interface {
+	// Frame reads the next record from r. It returns the number of bytes
+	// consumed from r and the record's payload.
+	//
+	// If r runs out of data before a complete record is available, Frame
+	// must return io.EOF together with the number of bytes it consumed.
+	// The tailer rewinds over those bytes and calls Frame again once more
+	// data has arrived, so a record is always framed from its first byte.
+	// Any other error is fatal and stops the tailer.
+	Frame(r *bufio.Reader) (n int, payload []byte, err error)
+}
+
+// lineFramer is the default framer. It splits on '\n' and strips the
+// trailing run of '\r' and '\n' from the payload, or exactly one "\r\n"
+// or "\n" in strict mode. With [WithDelimiters] it splits on any of the
+// bytes in delims instead; the rules for '\n' then apply when it is one
+// of them, and any other delimiter is stripped on its own.
+type lineFramer struct {
+	strict bool
+	delims string
+}
+
+func (f lineFramer) Frame(r *bufio.Reader) (int, []byte, error) {
+	n, payload, _, err := f.frame(r)
+	return n, payload, err
+}
+
+// frame is Frame that also returns the terminator stripped from the
+// payload.
+func (f lineFramer) frame(r *bufio.Reader) (int, []byte, []byte, error) {
+	line, err := f.read(r)
+	if err != nil {
+		return len(line), line, nil, err
+	}
+	payload, term := f.split(line)
+	return len(line), payload, term, nil
+}
+
+// read reads up to and including the next delimiter.
+func (f lineFramer) read(r *bufio.Reader) ([]byte, error) {
+	if f.delims == "" {
+		return r.ReadBytes('\n')
+	}
+
+	var line []byte
+	for {
+		if r.Buffered() == 0 {
+			if _, err := r.Peek(1); err != nil {
+				return line, err
+			}
+		}
+		buf, _ := r.Peek(r.Buffered())
+		if i := f.indexDelim(buf); i >= 0 {
+			line = append(line, buf[:i+1]...)
+			r.Discard(i + 1)
+			return line, nil
+		}
+		line = append(line, buf...)
+		r.Discard(len(buf))
+	}
+}
+
+// indexDelim returns the index of the first delimiter in buf, or -1.
+func (f lineFramer) indexDelim(buf []byte) int {
+	for i, c := range buf {
+		if strings.IndexByte(f.delims, c) >= 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// split separates a complete line ending in a delimiter into its payload
+// and terminator.
+func (f lineFramer) split(line []byte) (payload, term []byte) {
+	if end := len(line) - 1; line[end] != '\n' {
+		return line[:end], line[end:]
+	}
+	if !f.strict {
+		payload = bytes.TrimRight(line, "\r\n")
+		return payload, line[len(payload):]
+	}
+	payload = line[:len(line)-1]
+	if n := len(payload); n > 0 && payload[n-1] == '\r' {
+		payload = payload[:n-1]
+	}
+	return payload, line[len(payload):]
+}
+
+// trimPartial strips from the payload of an incomplete line the '\r'
+// that a "\r\n" split across writes leaves at its end, as split would
+// once the '\n' arrives.
+func (f lineFramer) trimPartial(payload []byte) []byte {
+	if f.delims != "" && strings.IndexByte(f.delims, '\n') < 0 {
+		return payload
+	}
+	if f.strict {
+		return bytes.TrimSuffix(payload, []byte("\r"))
+	}
+	return bytes.TrimRight(payload, "\r")
+}
+
+// LengthPrefixFramer returns a [Framer] for records that are preceded by
+// a 4-byte unsigned length, encoded with order, giving the size of the
+// payload that follows. Records larger than maxSize bytes are a fatal
+// error; a maxSize of zero or less means no limit.
+func LengthPrefixFramer(order binary.ByteOrder, maxSize int) Framer {
+	return lengthPrefixFramer{order: order, maxSize: maxSize}
+}
+
+type lengthPrefixFramer struct {
+	order   binary.ByteOrder
+	maxSize int
+}
+
+func (f lengthPrefixFramer) Frame(r *bufio.Reader) (int, []byte, error) {
+	var header [4]byte
+	n, err := io.ReadFull(r, header[:])
+	if err != nil {
+		return n, nil, eofOrErr(err)
+	}
+
+	size := f.order.Uint32(header[:])
+	if f.maxSize > 0 && uint64(size) > uint64(f.maxSize) {
+		return n, nil, fmt.Errorf("record of %d bytes exceeds limit of %d", size, f.maxSize)
+	}
+
+	payload := make([]byte, size)
+	m, err := io.ReadFull(r, payload)
+	if err != nil {
+		return n + m, nil, eofOrErr(err)
+	}
+	return n + m, payload, nil
+}
+
+// fixedFramer frames records of a fixed size, for [WithFixedRecordSize].
+type fixedFramer struct {
+	size int
+}
+
+func (f fixedFramer) Frame(r *bufio.Reader) (int, []byte, error) {
+	payload := make([]byte, f.size)
+	n, err := io.ReadFull(r, payload)
+	if err != nil {
+		return n, nil, eofOrErr(err)
+	}
+	return n, payload, nil
+}
+
+// eofOrErr maps the short-read errors of io.ReadFull to io.EOF, as the
+// [Framer] contract requires for incomplete records.
+func eofOrErr(err error) error {
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return io.EOF
+	}
+	return err
+}
@@ -0,0 +1,294 @@
+package tailf
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func lengthPrefixed(payload string) []byte {
+	b := binary.BigEndian.AppendUint32(nil, uint32(len(payload)))
+	return append(b, payload...)
+}
+
+func TestFollowLengthPrefixFramer(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.bin")
+
+	// A record containing a newline proves the default framing is not used.
+	if err := os.WriteFile(path, lengthPrefixed("first\nrecord"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path,
+		WithFromStart(true),
+		WithFramer(LengthPrefixFramer(binary.BigEndian, 1024)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case line := <-tailer.Lines():
+		if line.Text != "first\nrecord" {
+			t.Errorf("got %q, want %q", line.Text, "first\nrecord")
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for first record")
+	}
+
+	// Write the second record in two halves, split inside the payload.
+	second := lengthPrefixed("second")
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.Write(second[:6])
+
+	time.Sleep(300 * time.Millisecond)
+	select {
+	case line := <-tailer.Lines():
+		t.Fatalf("should not have received a record yet, got %q", line.Text)
+	default:
+	}
+
+	f.Write(second[6:])
+
+	select {
+	case line := <-tailer.Lines():
+		if line.Text != "second" {
+			t.Errorf("got %q, want %q", line.Text, "second")
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for second record")
+	}
+
+	cancel()
+	<-tailer.Done()
+}
+
+func TestLineFramerTrim(t *testing.T) {
+	tests := []struct {
+		in     string
+		strict bool
+		want   string
+	}{
+		{"x\r\r\n", false, "x"},
+		{"x\r\r\n", true, "x\r"},
+		{"x\r\n", true, "x"},
+		{"x\n", true, "x"},
+		{"\r\n", true, ""},
+		{"\n", true, ""},
+	}
+	for _, tt := range tests {
+		f := lineFramer{strict: tt.strict}
+		n, payload, err := f.Frame(bufio.NewReader(strings.NewReader(tt.in)))
+		if err != nil {
+			t.Fatalf("Frame(%q): %v", tt.in, err)
+		}
+		if n != len(tt.in) || string(payload) != tt.want {
+			t.Errorf("Frame(%q, strict=%v) = %d, %q; want %d, %q",
+				tt.in, tt.strict, n, payload, len(tt.in), tt.want)
+		}
+	}
+}
+
+func TestFollowStrictCRLF(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("x\r\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFromStart(true), WithStrictCRLF(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case line := <-tailer.Lines():
+		if line.Text != "x\r" {
+			t.Errorf("got %q, want %q", line.Text, "x\r")
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+
+	cancel()
+	<-tailer.Done()
+}
+
+func TestFollowTerminator(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("crlf\r\nlf\nno newline"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFromStart(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []Line{{Text: "crlf", Terminator: "\r\n"}, {Text: "lf", Terminator: "\n"}} {
+		select {
+		case line := <-tailer.Lines():
+			if line.Text != want.Text || line.Terminator != want.Terminator {
+				t.Errorf("got %q+%q, want %q+%q", line.Text, line.Terminator, want.Text, want.Terminator)
+			}
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+	}
+
+	// The unterminated last line is held back until it is complete.
+	<-tailer.CaughtUp()
+	select {
+	case line := <-tailer.Lines():
+		t.Errorf("got unterminated line %q", line.Text)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	cancel()
+	<-tailer.Done()
+}
+
+func TestReadAllDelimiters(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	data := "first\nsecond\x1ethird\r\n\x1efourth\x1elast"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	lines, err := ReadAll(ctx, path, WithDelimiters('\n', 0x1e))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Line{
+		{Text: "first", Terminator: "\n"},
+		{Text: "second", Terminator: "\x1e"},
+		{Text: "third", Terminator: "\r\n"},
+		{Text: "fourth", Terminator: "\x1e"},
+		{Text: "last", Partial: true},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d", len(lines), len(want))
+	}
+	for i, w := range want {
+		l := lines[i]
+		if l.Text != w.Text || l.Terminator != w.Terminator || l.Partial != w.Partial {
+			t.Errorf("line %d: got %q+%q partial=%v, want %q+%q partial=%v",
+				i, l.Text, l.Terminator, l.Partial, w.Text, w.Terminator, w.Partial)
+		}
+	}
+}
+
+func TestLineFramerDelimitersAcrossBuffer(t *testing.T) {
+	// A record longer than the reader's buffer is assembled from
+	// several reads.
+	long := strings.Repeat("x", 40)
+	r := bufio.NewReaderSize(strings.NewReader(long+"\x1erest"), 16)
+	f := lineFramer{delims: "\n\x1e"}
+
+	n, payload, term, err := f.frame(r)
+	if err != nil || n != len(long)+1 || string(payload) != long || string(term) != "\x1e" {
+		t.Errorf("frame = %d, %q, %q, %v; want %d, %q, %q", n, payload, term, err, len(long)+1, long, "\x1e")
+	}
+	n, payload, _, err = f.frame(r)
+	if err != io.EOF || n != 4 || string(payload) != "rest" {
+		t.Errorf("frame at EOF = %d, %q, %v; want 4, %q, EOF", n, payload, err, "rest")
+	}
+}
+
+func TestFollowFixedRecordSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.bin")
+
+	// Twelve-byte records, with newlines and zero bytes inside them, read
+	// through a buffer they do not divide evenly.
+	record := func(i int) []byte {
+		return []byte{'r', 'e', 'c', byte('0' + i), '\n', 0, 0, 0xff, 'x', 'y', 'z', '\n'}
+	}
+	var data []byte
+	for i := range 5 {
+		data = append(data, record(i)...)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path,
+		WithFromStart(true),
+		WithBufSize(16),
+		WithPollInterval(10*time.Millisecond),
+		WithFixedRecordSize(12),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := func(i int) {
+		t.Helper()
+		select {
+		case line := <-tailer.Lines():
+			want := record(i)
+			if string(line.Raw) != string(want) || line.Text != string(want) {
+				t.Errorf("record %d: got %q, raw %q, want %q", i, line.Text, line.Raw, want)
+			}
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for record %d", i)
+		}
+	}
+	for i := range 5 {
+		expect(i)
+	}
+
+	// A record written in two halves is delivered once it is complete.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.Write(record(5)[:7])
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case line := <-tailer.Lines():
+		t.Fatalf("got %q before the record was complete", line.Text)
+	default:
+	}
+	f.Write(append(record(5)[7:], record(6)...))
+	expect(5)
+	expect(6)
+
+	// Truncation discards the partial record and reads from the start.
+	f.Write(record(7)[:3])
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(path, record(8), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expect(8)
+}
@@ -0,0 +1,119 @@
+package tailf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FollowGlob follows every regular file matching the [filepath.Match]
+// pattern, such as "/var/log/app/*.log", and merges their lines into one
+// channel as [FollowMulti] does, with [Line].Source set to the path each
+// line was read from. The pattern is matched again every rescan interval
+// (see [WithRescanInterval]), and files that have come to match since
+// are followed from their start, so lines written before they were found
+// are not missed. A file is followed by its path: once followed, it is
+// never added twice, and its rotation or removal is handled by its own
+// tailer as under Follow.
+//
+// No file need match at first. FollowGlob fails if the pattern is
+// malformed or a file matching at the start cannot be opened; a file
+// found by a rescan that cannot be opened is tried again at the next
+// one. With [WithStopAtEOF], the files matching at the start are read to
+// their end and there is no rescan. Options apply as for [Follow];
+// [WithFromStart], [WithStartAtPercent] and [WithInitialBytes] affect
+// only the files matching at the start. Tailing stops when ctx is
+// cancelled.
+func FollowGlob(ctx context.Context, pattern string, opts ...Option) (*MultiTailer, error) {
+	o := defaults()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	paths, err := globFiles(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("tailf: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	m := &MultiTailer{
+		lines: make(chan Line, lineBuffer),
+		done:  make(chan struct{}),
+	}
+	followed := make(map[string]bool)
+	for _, path := range paths {
+		t, err := Follow(ctx, path, opts...)
+		if err != nil {
+			cancel()
+			for _, t := range m.tailers {
+				<-t.Done()
+			}
+			return nil, fmt.Errorf("tailf: %s: %w", path, errors.Unwrap(err))
+		}
+		m.add(ctx, path, t)
+		followed[path] = true
+	}
+
+	if !o.stopAtEOF {
+		newOpts := append(opts[:len(opts):len(opts)], readFromStart())
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			m.rescan(ctx, pattern, o.rescanInterval, followed, newOpts)
+		}()
+	}
+	go m.finish(cancel)
+	return m, nil
+}
+
+// rescan matches pattern every interval and follows, with opts, the files
+// that are not in followed yet, until ctx is cancelled.
+func (m *MultiTailer) rescan(ctx context.Context, pattern string, interval time.Duration, followed map[string]bool, opts []Option) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		paths, _ := globFiles(pattern)
+		for _, path := range paths {
+			if followed[path] {
+				continue
+			}
+			t, err := Follow(ctx, path, opts...)
+			if err != nil {
+				continue
+			}
+			m.add(ctx, path, t)
+			followed[path] = true
+		}
+	}
+}
+
+// readFromStart reads a file from its start, whatever [WithStartAtPercent]
+// and [WithInitialBytes] say, for a file whose contents are all new.
+func readFromStart() Option {
+	return func(o *options) {
+		o.fromStart = true
+		o.startPercent, o.initialBytes = -1, 0
+	}
+}
+
+// globFiles returns the regular files matching pattern, in lexical order.
+func globFiles(pattern string) ([]string, error) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, m := range matches {
+		if info, err := os.Stat(m); err == nil && info.Mode().IsRegular() {
+			paths = append(paths, m)
+		}
+	}
+	return paths, nil
+}
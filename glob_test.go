@@ -0,0 +1,148 @@
+package tailf
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFollowGlob(t *testing.T) {
+	tmp := t.TempDir()
+	a := filepath.Join(tmp, "a.log")
+	if err := os.WriteFile(a, []byte("a old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(tmp, "dir.log"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	m, err := FollowGlob(ctx, filepath.Join(tmp, "*.log"),
+		WithPollInterval(10*time.Millisecond),
+		WithRescanInterval(20*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := func() Line {
+		t.Helper()
+		select {
+		case line := <-m.Lines():
+			return line
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for a line")
+			return Line{}
+		}
+	}
+
+	appendLine(t, a, "a new")
+	if line := next(); line.Text != "a new" || line.Source != a {
+		t.Errorf("got %q from %s, want %q from %s", line.Text, line.Source, "a new", a)
+	}
+
+	// A file that comes to match is followed from its start; one that
+	// does not match is left alone.
+	if err := os.WriteFile(filepath.Join(tmp, "notes.txt"), []byte("skip\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	b := filepath.Join(tmp, "b.log")
+	if err := os.WriteFile(b, []byte("b 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if line := next(); line.Text != "b 1" || line.Source != b {
+		t.Errorf("got %q from %s, want %q from %s", line.Text, line.Source, "b 1", b)
+	}
+	appendLine(t, b, "b 2")
+	if line := next(); line.Text != "b 2" {
+		t.Errorf("got %q, want %q", line.Text, "b 2")
+	}
+
+	cancel()
+	<-m.Done()
+	if err := m.Err(); err != nil {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestFollowGlobStopAtEOF(t *testing.T) {
+	tmp := t.TempDir()
+	for _, name := range []string{"a.log", "b.log"} {
+		if err := os.WriteFile(filepath.Join(tmp, name), []byte(name+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	m, err := FollowGlob(ctx, filepath.Join(tmp, "*.log"), WithFromStart(true), WithStopAtEOF(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for line := range m.Lines() {
+		got = append(got, line.Text)
+	}
+	slices.Sort(got)
+	if want := []string{"a.log", "b.log"}; !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if _, err := FollowGlob(ctx, filepath.Join(tmp, "["), WithStopAtEOF(true)); !errors.Is(err, filepath.ErrBadPattern) {
+		t.Errorf("got %v, want filepath.ErrBadPattern", err)
+	}
+}
+
+func TestFollowGlobInitialBytes(t *testing.T) {
+	tmp := t.TempDir()
+	a := filepath.Join(tmp, "a.log")
+	if err := os.WriteFile(a, []byte("a 1\na 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	m, err := FollowGlob(ctx, filepath.Join(tmp, "*.log"),
+		WithInitialBytes(4),
+		WithPollInterval(10*time.Millisecond),
+		WithRescanInterval(20*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := func() string {
+		t.Helper()
+		select {
+		case line := <-m.Lines():
+			return line.Text
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for a line")
+			return ""
+		}
+	}
+
+	// The file matching at the start begins at its last 4 bytes.
+	if got := next(); got != "a 2" {
+		t.Errorf("got %q, want %q", got, "a 2")
+	}
+
+	// A file found by a rescan is read from its start regardless.
+	if err := os.WriteFile(filepath.Join(tmp, "b.log"), []byte("b 1\nb 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"b 1", "b 2"} {
+		if got := next(); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+
+	cancel()
+	<-m.Done()
+}
@@ -0,0 +1,117 @@
+package tailf
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+)
+
+// gzipSource reads the decompressed content of a growing stream of
+// concatenated gzip members, as enabled by [WithGzipInput]. It decodes
+// one complete member at a time; an incomplete member reads as io.EOF
+// and is decoded again from its start once more data has arrived.
+//
+// Decompressed data is kept until released, so that the tailer can
+// rewind over an incomplete record as it does on an ordinary file.
+type gzipSource struct {
+	file *os.File
+	off  int64 // offset in file of the next member
+
+	data []byte // decompressed data, from the oldest unreleased byte
+	pos  int    // read position in data
+	keep int    // data before keep has been released
+}
+
+// newGzipSource returns a source for the members of file from its
+// current position.
+func newGzipSource(file *os.File) *gzipSource {
+	off, _ := file.Seek(0, io.SeekCurrent)
+	return &gzipSource{file: file, off: off}
+}
+
+func (g *gzipSource) Read(p []byte) (int, error) {
+	if g.pos == len(g.data) {
+		if err := g.decode(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, g.data[g.pos:])
+	g.pos += n
+	return n, nil
+}
+
+// unread moves the read position back by n bytes.
+func (g *gzipSource) unread(n int64) {
+	g.pos = max(g.pos-int(n), g.keep)
+}
+
+// release marks everything read so far, except the last buffered bytes
+// that have not been consumed yet, as no longer needed for rewinding.
+func (g *gzipSource) release(buffered int) {
+	g.keep = max(g.pos-buffered, g.keep)
+}
+
+// decode appends the content of the next member to data. It returns
+// io.EOF if the file does not hold a complete member past off yet.
+func (g *gzipSource) decode() error {
+	info, err := g.file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() <= g.off {
+		return io.EOF
+	}
+
+	r := &countingReader{r: bufio.NewReader(io.NewSectionReader(g.file, g.off, info.Size()-g.off))}
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return gzipEOF(err)
+	}
+	zr.Multistream(false)
+	content, err := io.ReadAll(zr)
+	if err != nil {
+		return gzipEOF(err)
+	}
+
+	// Drop released data before growing the buffer.
+	if g.keep > 0 {
+		g.data = append(g.data[:0], g.data[g.keep:]...)
+		g.pos -= g.keep
+		g.keep = 0
+	}
+	g.data = append(g.data, content...)
+	g.off += r.n
+	return nil
+}
+
+// gzipEOF maps the errors of a truncated member to io.EOF.
+func gzipEOF(err error) error {
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return io.EOF
+	}
+	return err
+}
+
+// countingReader counts the bytes consumed through it. It implements
+// io.ByteReader so that the gzip and flate readers use it directly
+// instead of reading ahead through a buffer of their own.
+type countingReader struct {
+	r *bufio.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.n++
+	}
+	return b, err
+}
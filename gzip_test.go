@@ -0,0 +1,86 @@
+package tailf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func gzipMember(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestFollowGzipInput(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log.gz")
+
+	if err := os.WriteFile(path, gzipMember(t, "one\ntwo\nthr"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	trigger := make(chan struct{})
+	tailer, err := Follow(ctx, path, WithFromStart(true), WithGzipInput(true), WithManualPoll(trigger))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := func(want string) {
+		t.Helper()
+		for {
+			select {
+			case line := <-tailer.Lines():
+				if line.Text != want {
+					t.Errorf("got %q, want %q", line.Text, want)
+				}
+				return
+			case trigger <- struct{}{}:
+			case <-ctx.Done():
+				t.Fatalf("timed out waiting for %q", want)
+			}
+		}
+	}
+	expect("one")
+	expect("two")
+	<-tailer.CaughtUp()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// The second member completes the line begun in the first. Until
+	// the member is complete, nothing is delivered.
+	second := gzipMember(t, "ee\nfour\n")
+	f.Write(second[:len(second)/2])
+	trigger <- struct{}{}
+	trigger <- struct{}{}
+	select {
+	case line := <-tailer.Lines():
+		t.Fatalf("got %q from an incomplete member", line.Text)
+	default:
+	}
+
+	f.Write(second[len(second)/2:])
+	expect("three")
+	expect("four")
+
+	cancel()
+	<-tailer.Done()
+}
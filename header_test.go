@@ -0,0 +1,99 @@
+package tailf
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testHeader is a 16-byte container header: magic, version and the
+// offset of the body, with a newline byte that must not end a line.
+var testHeader = []byte("TLF1\x00\x02\n\x00\x00\x00\x00\x00\x00\x00\x00\x10")
+
+func TestReadAllSkipHeaderBytes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	content := append(bytes.Clone(testHeader), "line one\nline two\n"...)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var header []byte
+	lines, err := ReadAll(context.Background(), path,
+		WithSkipHeaderBytes(int64(len(testHeader))),
+		WithOnHeader(func(h []byte) { header = h }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 2 || lines[0].Text != "line one" || lines[1].Text != "line two" {
+		t.Errorf("got %v, want the two body lines", lines)
+	}
+	if !bytes.Equal(header, testHeader) {
+		t.Errorf("got header %q, want %q", header, testHeader)
+	}
+}
+
+func TestFollowSkipHeaderBytesIncomplete(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, testHeader[:10], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	headers := make(chan []byte, 1)
+	tailer, err := Follow(ctx, path,
+		WithFromStart(true),
+		WithPollInterval(10*time.Millisecond),
+		WithSkipHeaderBytes(int64(len(testHeader))),
+		WithOnHeader(func(h []byte) { headers <- h }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-tailer.CaughtUp()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(testHeader[10:])
+	f.WriteString("body\n")
+	f.Close()
+
+	if got := nextLine(ctx, t, tailer); got != "body" {
+		t.Errorf("got %q, want %q", got, "body")
+	}
+	if h := <-headers; !bytes.Equal(h, testHeader) {
+		t.Errorf("got header %q, want %q", h, testHeader)
+	}
+}
+
+func TestFollowSkipHeaderBytesFromEnd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	content := append(bytes.Clone(testHeader), "old\n"...)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Starting at the end, no header is expected in what follows.
+	tailer, err := Follow(ctx, path,
+		WithPollInterval(10*time.Millisecond),
+		WithSkipHeaderBytes(int64(len(testHeader))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-tailer.CaughtUp()
+	appendLine(t, path, "new")
+	if got := nextLine(ctx, t, tailer); got != "new" {
+		t.Errorf("got %q, want %q", got, "new")
+	}
+}
@@ -0,0 +1,37 @@
+package tailf
+
+import "os"
+
+// identify records the identity of the current file, described by info,
+// which rotation detection compares with the file found at the path,
+// and whether the path is a symbolic link.
+func (s *tailState) identify(info os.FileInfo) {
+	s.fileID = getFileIdentity(info)
+	s.customID = s.customIdentity(info)
+	s.isLink = isSymlink(s.path)
+}
+
+// customIdentity returns the [WithIdentityFunc] identity of the file at
+// the current path described by info, or "" if there is none.
+func (s *tailState) customIdentity(info os.FileInfo) string {
+	if s.o.identity == nil {
+		return ""
+	}
+	id, err := s.o.identity(info, s.path)
+	if err != nil {
+		return ""
+	}
+	return id
+}
+
+// replacedBy reports whether pathInfo, a stat of the path, describes a
+// different file than the current one. Under [WithIdentityFunc] the
+// custom identities decide; an unknown one never counts as a
+// replacement.
+func (s *tailState) replacedBy(pathInfo os.FileInfo) bool {
+	if s.o.identity == nil {
+		return s.fileID.replacedBy(getFileIdentity(pathInfo))
+	}
+	id := s.customIdentity(pathInfo)
+	return s.customID != "" && id != "" && id != s.customID
+}
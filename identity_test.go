@@ -0,0 +1,93 @@
+package tailf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFollowIdentityFunc(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "current")
+	gen1 := filepath.Join(tmp, "app.1")
+	gen2 := filepath.Join(tmp, "app.2")
+
+	if err := os.WriteFile(gen1, []byte("gen1 old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("app.1", path); err != nil {
+		t.Skipf("symlink: %v", err)
+	}
+
+	// The identity is the generation suffix of the symlink target.
+	suffix := func(info os.FileInfo, path string) (string, error) {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Ext(target), nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events := make(chan Event, 4)
+	tailer, err := Follow(ctx, path,
+		WithPollInterval(10*time.Millisecond),
+		WithIdentityFunc(suffix),
+		WithEventHandler(func(e Event) { events <- e }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Replacing the target with a new inode keeps its generation, so it
+	// is not a rotation under the custom identity.
+	tmpGen1 := filepath.Join(tmp, "app.1.tmp")
+	if err := os.WriteFile(tmpGen1, []byte("gen1 old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmpGen1, gen1); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case e := <-events:
+		t.Fatalf("got %v event for a replaced file of the same generation", e.Type)
+	default:
+	}
+
+	// Pointing the symlink at the next generation is a rotation.
+	if err := os.WriteFile(gen2, []byte("gen2 line\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	swap := filepath.Join(tmp, "current.tmp")
+	if err := os.Symlink("app.2", swap); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(swap, path); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case line := <-tailer.Lines():
+		if line.Text != "gen2 line" {
+			t.Errorf("got %q, want %q", line.Text, "gen2 line")
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the rotated file")
+	}
+	select {
+	case e := <-events:
+		if e.Type != EventRotated {
+			t.Errorf("got %v event, want %v", e.Type, EventRotated)
+		}
+	default:
+		t.Error("no rotation event")
+	}
+
+	cancel()
+	<-tailer.Done()
+}
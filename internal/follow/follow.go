@@ -0,0 +1,75 @@
+// Package follow runs the loop shared by the subpackages that tail a
+// file and parse each of its lines into a value of their own.
+package follow
+
+import (
+	"context"
+
+	"github.com/Splat/go-tailf"
+)
+
+// Parsed tails the file at path, as [tailf.Follow] does with opts, and
+// parses each line with parse. Parsed values are delivered on the first
+// channel, and parse errors on the second, followed by the tailer's
+// fatal error, if any. Marker lines are skipped. Both channels are
+// closed once the tailer stops, which happens when ctx is cancelled or
+// the returned stop function is called.
+//
+// The channels are unbuffered, so values and errors arrive in the order
+// of their lines. Once ctx is cancelled or stop is called, nothing more
+// is waited for, the fatal error included: a consumer that has stopped
+// receiving does not keep the goroutine alive.
+func Parsed[T any](ctx context.Context, path string, opts []tailf.Option, parse func(tailf.Line) (T, error)) (<-chan T, <-chan error, func()) {
+	ctx, stop := context.WithCancel(ctx)
+	values := make(chan T)
+	errs := make(chan error)
+
+	t, err := tailf.Follow(ctx, path, opts...)
+	go func() {
+		defer stop()
+		defer close(values)
+		defer close(errs)
+		if err != nil {
+			send(ctx, errs, err)
+			return
+		}
+		for line := range t.Lines() {
+			if line.Marker != tailf.NoMarker {
+				continue
+			}
+			v, err := parse(line)
+			if err != nil {
+				if !send(ctx, errs, err) {
+					break
+				}
+				continue
+			}
+			if !send(ctx, values, v) {
+				break
+			}
+		}
+		// The loop only ends early once ctx is done, so the tailer is
+		// stopping either way.
+		<-t.Done()
+		if err := t.Err(); err != nil {
+			send(ctx, errs, err)
+		}
+	}()
+	return values, errs, stop
+}
+
+// send delivers v on ch, preferring that to giving up when a receiver is
+// ready and ctx is done at once. It reports false if ctx was done first.
+func send[T any](ctx context.Context, ch chan<- T, v T) bool {
+	select {
+	case ch <- v:
+		return true
+	default:
+	}
+	select {
+	case ch <- v:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
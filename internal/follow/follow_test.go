@@ -0,0 +1,82 @@
+package follow
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Splat/go-tailf"
+)
+
+func upper(line tailf.Line) (string, error) {
+	if line.Text == "bad" {
+		return "", errors.New("bad line")
+	}
+	return strings.ToUpper(line.Text), nil
+}
+
+func TestParsed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("one\nbad\ntwo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	values, errs, stop := Parsed(ctx, path, []tailf.Option{tailf.WithFromStart(true), tailf.WithStopAtEOF(true)}, upper)
+	defer stop()
+
+	var got []string
+	for values != nil || errs != nil {
+		select {
+		case v, ok := <-values:
+			if !ok {
+				values = nil
+				continue
+			}
+			got = append(got, v)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			got = append(got, "error: "+err.Error())
+		case <-ctx.Done():
+			t.Fatalf("timed out after %q", got)
+		}
+	}
+	if want := "ONE|error: bad line|TWO"; strings.Join(got, "|") != want {
+		t.Errorf("got %q, want %q", strings.Join(got, "|"), want)
+	}
+}
+
+func TestParsedStopWithoutDraining(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("bad\npartial"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nobody receives the parse error, nor the ErrTrailingPartial the
+	// tailer then stops with.
+	values, _, stop := Parsed(context.Background(), path, []tailf.Option{
+		tailf.WithFromStart(true),
+		tailf.WithStopAtEOF(true),
+		tailf.WithRequireFinalNewline(true),
+	}, upper)
+	time.Sleep(50 * time.Millisecond)
+	stop()
+
+	select {
+	case _, ok := <-values:
+		if ok {
+			t.Fatal("got a value, want the channel closed")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("channels not closed after stop")
+	}
+}
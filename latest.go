@@ -0,0 +1,94 @@
+package tailf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// ErrNoMatch is returned by [FollowLatest] when no file matches the pattern.
+var ErrNoMatch = errors.New("no file matches pattern")
+
+// FollowLatest tails the most recently modified file in dir whose name
+// matches the [filepath.Match] pattern glob, and keeps following the
+// newest match as new files appear.
+//
+// Exactly one file is followed at a time. Whenever the current file has
+// been read to EOF, the directory is rescanned; if a matching file not
+// followed before has been modified more recently, the tailer switches
+// to it, reads it from the start and emits an [EventSwitched] event
+// carrying the old and new paths. A file already followed is never
+// switched back to, even if it is written to again, so that a late write
+// to an older file does not deliver it a second time. Options apply as
+// for [Follow]; [WithFromStart] affects only the first file.
+func FollowLatest(ctx context.Context, dir, glob string, opts ...Option) (*Tailer, error) {
+	o := defaults()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	pattern := filepath.Join(dir, glob)
+	followed := make(map[string]os.FileInfo)
+	path, err := latestMatch(pattern, "", followed)
+	if err != nil {
+		return nil, fmt.Errorf("tailf: %w", err)
+	}
+	if path == "" {
+		return nil, fmt.Errorf("tailf: %w: %s", ErrNoMatch, pattern)
+	}
+
+	s, err := newTailState(path, o)
+	if err != nil {
+		return nil, fmt.Errorf("tailf: %w", err)
+	}
+	s.next = func(current string) string {
+		path, err := latestMatch(pattern, current, followed)
+		if err != nil || path == "" {
+			return current
+		}
+		return path
+	}
+	return s.start(ctx), nil
+}
+
+// latestMatch returns the most recently modified regular file matching
+// pattern, or "" if there is none, and records it in followed. Files in
+// followed other than current are passed over, unless the path now holds
+// another file, and entries for paths no longer matching are dropped.
+// Ties on modification time are won by current, so the tailer never
+// flaps between equally old files.
+func latestMatch(pattern, current string, followed map[string]os.FileInfo) (string, error) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return "", err
+	}
+
+	var best string
+	var bestInfo os.FileInfo
+	for _, m := range matches {
+		info, err := os.Stat(m)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if prev, ok := followed[m]; ok && m != current && os.SameFile(prev, info) {
+			continue
+		}
+		if bestInfo == nil || info.ModTime().After(bestInfo.ModTime()) ||
+			(info.ModTime().Equal(bestInfo.ModTime()) && m == current) {
+			best, bestInfo = m, info
+		}
+	}
+
+	for path := range followed {
+		if !slices.Contains(matches, path) {
+			delete(followed, path)
+		}
+	}
+	if best != "" {
+		followed[best] = bestInfo
+	}
+	return best, nil
+}
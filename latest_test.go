@@ -0,0 +1,131 @@
+package tailf
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFollowLatest(t *testing.T) {
+	tmp := t.TempDir()
+	older := filepath.Join(tmp, "build-1.log")
+	newer := filepath.Join(tmp, "build-2.log")
+
+	if err := os.WriteFile(older, []byte("stale build\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(newer, []byte("first build\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(older, past, past); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(newer, past.Add(time.Minute), past.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	events := make(chan Event, 4)
+	tailer, err := FollowLatest(ctx, tmp, "build-*.log",
+		WithFromStart(true),
+		WithEventHandler(func(e Event) { events <- e }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case line := <-tailer.Lines():
+		if line.Text != "first build" {
+			t.Errorf("got %q, want %q", line.Text, "first build")
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for line from newest file")
+	}
+
+	// A new build starts; its whole content should be followed.
+	latest := filepath.Join(tmp, "build-3.log")
+	if err := os.WriteFile(latest, []byte("second build\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case line := <-tailer.Lines():
+		if line.Text != "second build" {
+			t.Errorf("got %q, want %q", line.Text, "second build")
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for line from switched file")
+	}
+
+	select {
+	case e := <-events:
+		if e.Type != EventSwitched || e.OldPath != newer || e.Path != latest {
+			t.Errorf("got event %+v, want switch from %s to %s", e, newer, latest)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for switch event")
+	}
+
+	cancel()
+	<-tailer.Done()
+}
+
+func TestFollowLatestNeverSwitchesBack(t *testing.T) {
+	tmp := t.TempDir()
+	first := filepath.Join(tmp, "build-1.log")
+	if err := os.WriteFile(first, []byte("first build\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(first, past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tailer, err := FollowLatest(ctx, tmp, "build-*.log",
+		WithFromStart(true),
+		WithPollInterval(10*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := nextLine(ctx, t, tailer); got != "first build" {
+		t.Errorf("got %q, want %q", got, "first build")
+	}
+
+	second := filepath.Join(tmp, "build-2.log")
+	if err := os.WriteFile(second, []byte("second build\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := nextLine(ctx, t, tailer); got != "second build" {
+		t.Errorf("got %q, want %q", got, "second build")
+	}
+
+	// A late write makes the first file the newest again, but it has
+	// been followed already and is not read a second time.
+	appendLine(t, first, "late write")
+	time.Sleep(50 * time.Millisecond)
+	appendLine(t, second, "more")
+	if got := nextLine(ctx, t, tailer); got != "more" {
+		t.Errorf("got %q, want %q", got, "more")
+	}
+
+	cancel()
+	<-tailer.Done()
+}
+
+func TestFollowLatestNoMatch(t *testing.T) {
+	_, err := FollowLatest(context.Background(), t.TempDir(), "*.log")
+	if !errors.Is(err, ErrNoMatch) {
+		t.Errorf("got %v, want ErrNoMatch", err)
+	}
+}
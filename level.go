@@ -0,0 +1,99 @@
+package tailf
+
+import (
+	"strconv"
+	"strings"
+)
+
+// LevelParser extracts a severity level from a line of text. It reports
+// false if the line carries no recognizable level.
+type LevelParser func(text string) (level string, ok bool)
+
+// syslogSeverities are the RFC 5424 severity keywords, indexed by code.
+var syslogSeverities = [8]string{
+	"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug",
+}
+
+// SyslogLevel returns a [LevelParser] for lines that start with a syslog
+// priority such as "<34>". The level is the severity keyword of the
+// priority, e.g. "crit".
+func SyslogLevel() LevelParser {
+	return func(text string) (string, bool) {
+		if !strings.HasPrefix(text, "<") {
+			return "", false
+		}
+		end := strings.IndexByte(text, '>')
+		if end < 2 || end > 4 {
+			return "", false
+		}
+		pri, err := strconv.Atoi(text[1:end])
+		if err != nil || pri < 0 || pri > 191 {
+			return "", false
+		}
+		return syslogSeverities[pri%8], true
+	}
+}
+
+// LogfmtLevel returns a [LevelParser] for logfmt lines, reading the value
+// of key, as in "level=warn". A quoted value is unquoted. An empty key
+// means "level".
+func LogfmtLevel(key string) LevelParser {
+	if key == "" {
+		key = "level"
+	}
+	prefix := key + "="
+	return func(text string) (string, bool) {
+		for rest := text; ; {
+			i := strings.Index(rest, prefix)
+			if i < 0 {
+				return "", false
+			}
+			// The key must start a field, not end another key.
+			if i > 0 && rest[i-1] != ' ' {
+				rest = rest[i+len(prefix):]
+				continue
+			}
+			value := rest[i+len(prefix):]
+			if strings.HasPrefix(value, `"`) {
+				if end := strings.IndexByte(value[1:], '"'); end >= 0 {
+					return value[1 : end+1], true
+				}
+				return "", false
+			}
+			if end := strings.IndexByte(value, ' '); end >= 0 {
+				value = value[:end]
+			}
+			return value, value != ""
+		}
+	}
+}
+
+// JSONLevel returns a [LevelParser] for JSON lines, reading the string
+// value of key, as in {"level":"error"}. It scans for the key rather than
+// decoding the line, so it stays cheap but does not handle escaped
+// quotes in the value. An empty key means "level".
+func JSONLevel(key string) LevelParser {
+	if key == "" {
+		key = "level"
+	}
+	quoted := `"` + key + `"`
+	return func(text string) (string, bool) {
+		i := strings.Index(text, quoted)
+		if i < 0 {
+			return "", false
+		}
+		rest := strings.TrimLeft(text[i+len(quoted):], " \t")
+		if !strings.HasPrefix(rest, ":") {
+			return "", false
+		}
+		rest = strings.TrimLeft(rest[1:], " \t")
+		if !strings.HasPrefix(rest, `"`) {
+			return "", false
+		}
+		end := strings.IndexByte(rest[1:], '"')
+		if end < 0 {
+			return "", false
+		}
+		return rest[1 : end+1], true
+	}
+}
@@ -0,0 +1,77 @@
+package tailf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLevelParsers(t *testing.T) {
+	tests := []struct {
+		name   string
+		parser LevelParser
+		text   string
+		want   string
+		ok     bool
+	}{
+		{"syslog", SyslogLevel(), "<34>Oct 11 22:14:15 host su: failed", "crit", true},
+		{"syslog debug", SyslogLevel(), "<191>msg", "debug", true},
+		{"syslog none", SyslogLevel(), "plain text", "", false},
+		{"syslog bad", SyslogLevel(), "<abc>msg", "", false},
+		{"logfmt", LogfmtLevel(""), `ts=1 level=warn msg="disk low"`, "warn", true},
+		{"logfmt quoted", LogfmtLevel(""), `level="error" msg=x`, "error", true},
+		{"logfmt other key", LogfmtLevel(""), `sublevel=3 msg=x`, "", false},
+		{"logfmt custom key", LogfmtLevel("lvl"), `lvl=info`, "info", true},
+		{"json", JSONLevel(""), `{"ts":1,"level":"error","msg":"x"}`, "error", true},
+		{"json spaced", JSONLevel(""), `{"level" : "info"}`, "info", true},
+		{"json number", JSONLevel(""), `{"level":3}`, "", false},
+		{"json none", JSONLevel(""), `{"msg":"level"}`, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := tt.parser(tt.text)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s: parse(%q) = %q, %v; want %q, %v", tt.name, tt.text, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFollowLevelParserWithFilter(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	content := "level=debug msg=noise\nlevel=error msg=broken\nlevel=debug msg=more\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path,
+		WithFromStart(true),
+		WithLevelParser(LogfmtLevel("")),
+		WithFilter(func(l Line) bool { return l.Level != "debug" }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case line := <-tailer.Lines():
+		if line.Level != "error" || line.Text != "level=error msg=broken" {
+			t.Errorf("got %+v, want the error line", line)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+
+	<-tailer.CaughtUp()
+	if got := tailer.Pending(); got != 0 {
+		t.Errorf("Pending = %d, want debug lines dropped", got)
+	}
+
+	cancel()
+	<-tailer.Done()
+}
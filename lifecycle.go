@@ -0,0 +1,134 @@
+package tailf
+
+import "time"
+
+// LifecycleType identifies the kind of a [LifecycleEvent].
+type LifecycleType int
+
+const (
+	// LifecycleStarted is sent when the tailing goroutine starts, before
+	// anything is read.
+	LifecycleStarted LifecycleType = iota + 1
+
+	// LifecycleOpened is sent when the file is opened at the start, or
+	// once it appears under [WithFollowByName].
+	LifecycleOpened
+
+	// LifecycleCaughtUp is sent when the initial backlog has been read,
+	// as [Tailer.CaughtUp] is closed.
+	LifecycleCaughtUp
+
+	// LifecycleRotated, LifecycleTruncated, LifecycleReopened and
+	// LifecycleSwitched are sent along with the [Event] of the same name.
+	LifecycleRotated
+	LifecycleTruncated
+	LifecycleReopened
+	LifecycleSwitched
+
+	// LifecyclePaused and LifecycleResumed are sent when [Tailer.Pause]
+	// and [Tailer.Resume] change the state of the tailer.
+	LifecyclePaused
+	LifecycleResumed
+
+	// LifecycleError is sent with a fatal error, whether it stops the
+	// tailer or [WithSupervise] restarts it.
+	LifecycleError
+
+	// LifecycleStopped is the last event, sent just before the Lines and
+	// Done channels are closed, with the error [Tailer.Err] returns and
+	// the final statistics.
+	LifecycleStopped
+)
+
+// String returns the name of the lifecycle event type.
+func (t LifecycleType) String() string {
+	switch t {
+	case LifecycleStarted:
+		return "started"
+	case LifecycleOpened:
+		return "opened"
+	case LifecycleCaughtUp:
+		return "caught-up"
+	case LifecycleRotated:
+		return "rotated"
+	case LifecycleTruncated:
+		return "truncated"
+	case LifecycleReopened:
+		return "reopened"
+	case LifecycleSwitched:
+		return "switched"
+	case LifecyclePaused:
+		return "paused"
+	case LifecycleResumed:
+		return "resumed"
+	case LifecycleError:
+		return "error"
+	case LifecycleStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
+
+// LifecycleEvent is a change in the state of a tailer, sent on the
+// [WithLifecycleEvents] channel.
+type LifecycleEvent struct {
+	// Type is the kind of change.
+	Type LifecycleType
+
+	// Time is when the change happened.
+	Time time.Time
+
+	// Path is the path being tailed.
+	Path string
+
+	// OldPath is the previously tailed path for [LifecycleSwitched].
+	OldPath string
+
+	// Offset is the read position in the file when the change happened,
+	// or -1 if there is no open file.
+	Offset int64
+
+	// Err is the error for [LifecycleError], and the error that stopped
+	// the tailer, if any, for [LifecycleStopped].
+	Err error
+
+	// Stats is the summary of the tailer's activity for
+	// [LifecycleStopped], as [Tailer.FinalStats] returns it, and nil for
+	// other events.
+	Stats *Stats
+}
+
+// lifecycle returns the lifecycle event type sent along with an event
+// of type e.
+func (e EventType) lifecycle() LifecycleType {
+	switch e {
+	case EventTruncated:
+		return LifecycleTruncated
+	case EventRotated:
+		return LifecycleRotated
+	case EventReopened:
+		return LifecycleReopened
+	default:
+		return LifecycleSwitched
+	}
+}
+
+// announce sends e, completed with the time, path and offset, to the
+// [WithLifecycleEvents] channel. The event is dropped if the channel is
+// not ready.
+func (s *tailState) announce(e LifecycleEvent) {
+	if s.o.lifecycle == nil {
+		return
+	}
+	e.Time = time.Now()
+	e.Path = s.path
+	e.Offset = -1
+	if s.file != nil {
+		e.Offset = s.offset()
+	}
+	select {
+	case s.o.lifecycle <- e:
+	default:
+	}
+}
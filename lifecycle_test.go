@@ -0,0 +1,164 @@
+package tailf
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFollowLifecycleEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events := make(chan LifecycleEvent, 16)
+	tailer, err := Follow(ctx, path,
+		WithFromStart(true),
+		WithPollInterval(10*time.Millisecond),
+		WithLifecycleEvents(events),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := func(want LifecycleType) LifecycleEvent {
+		t.Helper()
+		select {
+		case e := <-events:
+			if e.Type != want {
+				t.Fatalf("got %v event, want %v", e.Type, want)
+			}
+			if e.Path != path || e.Time.IsZero() {
+				t.Errorf("%v: got path %q, time %v", e.Type, e.Path, e.Time)
+			}
+			return e
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %v", want)
+			return LifecycleEvent{}
+		}
+	}
+
+	next(LifecycleStarted)
+	if e := next(LifecycleOpened); e.Offset != 0 {
+		t.Errorf("opened: got offset %d, want 0", e.Offset)
+	}
+	<-tailer.Lines()
+	<-tailer.Lines()
+	if e := next(LifecycleCaughtUp); e.Offset != 8 {
+		t.Errorf("caught up: got offset %d, want 8", e.Offset)
+	}
+
+	if err := tailer.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tailer.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tailer.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	next(LifecyclePaused)
+	next(LifecycleResumed)
+
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	next(LifecycleTruncated)
+
+	cancel()
+	<-tailer.Done()
+	if e := next(LifecycleStopped); e.Err != nil || e.Stats == nil || e.Stats.StopReason != StopCancelled {
+		t.Errorf("stopped: got error %v, stats %+v", e.Err, e.Stats)
+	}
+	select {
+	case e := <-events:
+		t.Errorf("got %v event after stopping", e.Type)
+	default:
+	}
+}
+
+func TestFinalStats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, []byte("one\ntwo\nthree\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events := make(chan LifecycleEvent, 16)
+	tailer, err := Follow(ctx, path, WithFromStart(true), WithStopAtEOF(true), WithLifecycleEvents(events))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st := tailer.FinalStats(); st.StopReason != NotStopped || !st.Stopped.IsZero() {
+		t.Errorf("before stopping: got %+v, want zero stats", st)
+	}
+	for range tailer.Lines() {
+	}
+	<-tailer.Done()
+
+	final := tailer.FinalStats()
+	if final.Lines != 3 || final.BytesRead != 14 || final.StopReason != StopEOF {
+		t.Errorf("got %d lines, %d bytes, %v, want 3, 14, eof", final.Lines, final.BytesRead, final.StopReason)
+	}
+	if final.Started.IsZero() || final.Stopped.Before(final.Started) {
+		t.Errorf("got started %v, stopped %v", final.Started, final.Stopped)
+	}
+	if again := tailer.FinalStats(); again != final {
+		t.Errorf("final stats changed:\n%+v\n%+v", final, again)
+	}
+
+	var stopped LifecycleEvent
+	for len(events) > 0 {
+		stopped = <-events
+	}
+	if stopped.Type != LifecycleStopped || stopped.Stats == nil || *stopped.Stats != final {
+		t.Errorf("stopped event: got %v with %+v, want %+v", stopped.Type, stopped.Stats, final)
+	}
+}
+
+func TestFinalStatsTrailingPartial(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, []byte("one\ntwo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events := make(chan LifecycleEvent, 16)
+	tailer, err := Follow(ctx, path,
+		WithFromStart(true),
+		WithStopAtEOF(true),
+		WithRequireFinalNewline(true),
+		WithLifecycleEvents(events),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range tailer.Lines() {
+	}
+	<-tailer.Done()
+
+	if err := tailer.Err(); !errors.Is(err, ErrTrailingPartial) {
+		t.Fatalf("got %v, want ErrTrailingPartial", err)
+	}
+	final := tailer.FinalStats()
+	if final.Lines != 1 || final.StopReason != StopEOF {
+		t.Errorf("got %d lines, %v, want 1, eof", final.Lines, final.StopReason)
+	}
+	var stopped LifecycleEvent
+	for len(events) > 0 {
+		stopped = <-events
+	}
+	if stopped.Type != LifecycleStopped || stopped.Stats == nil || stopped.Stats.StopReason != StopEOF {
+		t.Errorf("stopped event: got %v with %+v, want eof", stopped.Type, stopped.Stats)
+	}
+}
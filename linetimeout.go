@@ -0,0 +1,39 @@
+package tailf
+
+import (
+	"context"
+	"time"
+)
+
+// partialExpired reports whether the incomplete line at the end of the
+// file has gone [WithLineTimeout] without growing. n is its current
+// length; the clock restarts whenever the length changes.
+func (s *tailState) partialExpired(n int) bool {
+	if n != s.pending {
+		s.partialSince = time.Now()
+	}
+	if s.o.lineTimeout <= 0 || n == 0 || s.scan.skipping() || s.scan.header > 0 {
+		return false
+	}
+	if _, ok := s.o.framer.(lineFramer); !ok {
+		return false
+	}
+	return time.Since(s.partialSince) >= s.o.lineTimeout
+}
+
+// flushPartial delivers the incomplete line at the end of the file as a
+// partial line and consumes its bytes, so that whatever completes it is
+// read as a line of its own. It returns false if ctx was cancelled.
+func (s *tailState) flushPartial(ctx context.Context) bool {
+	n := s.scan.pending
+	payload := s.scan.partialPayload()
+	s.t.recordRead(n)
+	s.pending = 0
+	s.scan.pending, s.scan.partial = 0, nil
+	if len(payload) == 0 {
+		return true
+	}
+	l := s.scan.line(payload, nil)
+	l.Partial = true
+	return s.deliver(ctx, l)
+}
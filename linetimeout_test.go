@@ -0,0 +1,76 @@
+package tailf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFollowLineTimeout(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path,
+		WithPollInterval(10*time.Millisecond),
+		WithLineTimeout(50*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	readLine := func() Line {
+		t.Helper()
+		select {
+		case line := <-tailer.Lines():
+			return line
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for a line")
+			return Line{}
+		}
+	}
+
+	// An idle fragment is flushed as a partial line.
+	start := time.Now()
+	f.WriteString("prompt> ")
+	line := readLine()
+	if line.Text != "prompt> " || !line.Partial || line.Terminator != "" {
+		t.Errorf("got %q partial=%v terminator=%q, want partial %q", line.Text, line.Partial, line.Terminator, "prompt> ")
+	}
+	if waited := time.Since(start); waited < 50*time.Millisecond {
+		t.Errorf("flushed after %v, before the timeout", waited)
+	}
+
+	// The late completion arrives as a line of its own.
+	f.WriteString("answer\n")
+	line = readLine()
+	if line.Text != "answer" || line.Partial || line.Terminator != "\n" {
+		t.Errorf("got %q partial=%v terminator=%q, want complete %q", line.Text, line.Partial, line.Terminator, "answer")
+	}
+
+	// A line completed within the timeout is delivered whole.
+	f.WriteString("quick")
+	time.Sleep(15 * time.Millisecond)
+	f.WriteString(" line\n")
+	line = readLine()
+	if line.Text != "quick line" || line.Partial {
+		t.Errorf("got %q partial=%v, want complete %q", line.Text, line.Partial, "quick line")
+	}
+
+	cancel()
+	<-tailer.Done()
+}
@@ -0,0 +1,144 @@
+// Package logfmt tails files of logfmt lines, such as
+// `level=info msg="request served" status=200 cached`, and parses each
+// line into its keys and values.
+package logfmt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/Splat/go-tailf"
+	"github.com/Splat/go-tailf/internal/follow"
+)
+
+// ParseError reports a line that is not valid logfmt.
+type ParseError struct {
+	// Line is the text of the malformed line.
+	Line string
+
+	// Reason says what is wrong with it.
+	Reason string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("logfmt: %s: %q", e.Reason, e.Line)
+}
+
+// FollowLogfmt tails the file at path, as [tailf.Follow] does with opts,
+// and delivers the fields of each line, as [Parse] returns them, on the
+// first channel. The second channel carries a [*ParseError] for each
+// malformed line and, last, the tailer's fatal error, if any. Tailing
+// stops when ctx is cancelled or the returned stop function is called,
+// and both channels are then closed.
+//
+// Receive from both channels until they are closed: a parse error that
+// is not received holds up the fields that follow it. After calling
+// stop, a caller may stop receiving altogether.
+func FollowLogfmt(ctx context.Context, path string, opts ...tailf.Option) (<-chan map[string]string, <-chan error, func()) {
+	return follow.Parsed(ctx, path, opts, func(line tailf.Line) (map[string]string, error) {
+		return Parse(line.Text)
+	})
+}
+
+// Parse parses a logfmt line into its fields. Pairs are separated by
+// spaces. A value is either bare, running up to the next space, or
+// double-quoted, with the escapes of a Go string literal such as \" and
+// \n. A key without "=" is a bare key, whose value is empty, as is the
+// value of a key followed by "=" alone. A key given twice keeps its last
+// value. The returned error is a [*ParseError].
+func Parse(text string) (map[string]string, error) {
+	fields := make(map[string]string)
+	s := text
+	for {
+		s = skipSpace(s)
+		if s == "" {
+			return fields, nil
+		}
+
+		n := scanBare(s)
+		if n == 0 {
+			if s[0] == '=' {
+				return nil, &ParseError{Line: text, Reason: "missing key"}
+			}
+			return nil, &ParseError{Line: text, Reason: "quote in key"}
+		}
+		key := s[:n]
+		s = s[n:]
+		if s == "" || isSpace(s[0]) {
+			fields[key] = ""
+			continue
+		}
+		if s[0] != '=' {
+			return nil, &ParseError{Line: text, Reason: "quote in key"}
+		}
+		s = s[1:]
+
+		value, rest, err := parseValue(s)
+		if err != nil {
+			return nil, &ParseError{Line: text, Reason: err.Error()}
+		}
+		if rest != "" && !isSpace(rest[0]) {
+			return nil, &ParseError{Line: text, Reason: "missing space after value"}
+		}
+		fields[key] = value
+		s = rest
+	}
+}
+
+// parseValue parses the value at the start of s, after the "=", and
+// returns the rest of s.
+func parseValue(s string) (string, string, error) {
+	if s == "" || isSpace(s[0]) {
+		return "", s, nil
+	}
+	if s[0] != '"' {
+		n := scanBare(s)
+		if n < len(s) && s[n] == '"' {
+			return "", s, errors.New("quote in bare value")
+		}
+		// An '=' in a bare value, as in a=b=c, is kept.
+		for n < len(s) && s[n] == '=' {
+			n++
+			n += scanBare(s[n:])
+		}
+		return s[:n], s[n:], nil
+	}
+
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			value, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", s, errors.New("bad escape in quoted value")
+			}
+			return value, s[i+1:], nil
+		}
+	}
+	return "", s, errors.New("unterminated quoted value")
+}
+
+// scanBare returns the length of the run of key or bare value bytes at
+// the start of s: anything but spaces, '=' and '"'.
+func scanBare(s string) int {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; isSpace(c) || c == '=' || c == '"' {
+			return i
+		}
+	}
+	return len(s)
+}
+
+func skipSpace(s string) string {
+	for s != "" && isSpace(s[0]) {
+		s = s[1:]
+	}
+	return s
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
@@ -0,0 +1,107 @@
+package logfmt
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Splat/go-tailf"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		line string
+		want map[string]string
+	}{
+		{`level=info msg=started`, map[string]string{"level": "info", "msg": "started"}},
+		{`msg="request served" status=200`, map[string]string{"msg": "request served", "status": "200"}},
+		{`msg="say \"hi\"" path="C:\\tmp"`, map[string]string{"msg": `say "hi"`, "path": `C:\tmp`}},
+		{`msg="two\nlines \u00e9"`, map[string]string{"msg": "two\nlines é"}},
+		{`cached level=debug`, map[string]string{"cached": "", "level": "debug"}},
+		{`empty= quoted=""`, map[string]string{"empty": "", "quoted": ""}},
+		{`query=a=b  	n=1 n=2`, map[string]string{"query": "a=b", "n": "2"}},
+		{`  `, map[string]string{}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.line)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		line, reason string
+	}{
+		{`=value`, "missing key"},
+		{`a=1 =2`, "missing key"},
+		{`"quoted"=1`, "quote in key"},
+		{`ke"y=1`, "quote in key"},
+		{`msg="unterminated`, "unterminated quoted value"},
+		{`msg="ends in escape\"`, "unterminated quoted value"},
+		{`msg="bad \q escape"`, "bad escape in quoted value"},
+		{`msg=ab"c`, "quote in bare value"},
+		{`msg="a"b=1`, "missing space after value"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.line)
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("Parse(%q): got %v, want a ParseError", tt.line, err)
+			continue
+		}
+		if pe.Line != tt.line || pe.Reason != tt.reason {
+			t.Errorf("Parse(%q): got %q for %q, want %q", tt.line, pe.Reason, pe.Line, tt.reason)
+		}
+	}
+}
+
+func TestFollowLogfmt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	content := "level=info msg=started\n" +
+		"msg=\"broken\n" +
+		"level=warn msg=\"disk almost full\"\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	records, errs, stop := FollowLogfmt(ctx, path, tailf.WithFromStart(true))
+	defer stop()
+
+	var got []string
+	for len(got) < 3 {
+		select {
+		case r := <-records:
+			got = append(got, r["level"]+": "+r["msg"])
+		case err := <-errs:
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("unexpected error %v", err)
+			}
+			got = append(got, "error: "+pe.Line)
+		case <-ctx.Done():
+			t.Fatalf("timed out after %q", got)
+		}
+	}
+	if want := []string{"info: started", `error: msg="broken`, "warn: disk almost full"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	stop()
+	for range records {
+	}
+	for range errs {
+	}
+}
@@ -0,0 +1,56 @@
+package tailf
+
+import "io"
+
+// MarkerFallback says what [WithStartAfterMarker] does when no line of
+// the initial content matches the marker.
+type MarkerFallback int
+
+const (
+	// MarkerSkipAll discards the initial content and follows the lines
+	// written after it.
+	MarkerSkipAll MarkerFallback = iota
+
+	// MarkerDeliverAll delivers the initial content as if no marker had
+	// been asked for.
+	MarkerDeliverAll
+)
+
+// beforeMarker reports whether l comes before the [WithStartAfterMarker]
+// marker, or is the marker itself and excluded, and must be skipped.
+func (s *tailState) beforeMarker(l Line) bool {
+	if !s.seekingMarker {
+		return false
+	}
+	if !s.o.startMarker(l.Text) {
+		return true
+	}
+	s.seekingMarker = false
+	return !s.o.markerInclusive
+}
+
+// markerNotFound ends the search for the [WithStartAfterMarker] marker
+// at the end of the initial content. Under [MarkerDeliverAll] it goes
+// back to where reading started and reports true, so that the content is
+// read again and delivered.
+func (s *tailState) markerNotFound() (bool, error) {
+	s.seekingMarker = false
+	if s.o.markerFallback != MarkerDeliverAll {
+		return false, nil
+	}
+	if _, err := s.file.Seek(s.startOffset, io.SeekStart); err != nil {
+		return false, s.fail("seek", err)
+	}
+	s.resetReader()
+	s.scan.torn = s.startTorn
+	s.pending = 0
+	return true, nil
+}
+
+// markStart records where reading of the current file starts, and
+// begins the search for the [WithStartAfterMarker] marker.
+func (s *tailState) markStart() {
+	s.seekingMarker = s.o.startMarker != nil
+	s.startTorn = s.scan.torn
+	s.startOffset, _ = s.position()
+}
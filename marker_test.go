@@ -0,0 +1,101 @@
+package tailf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadAllStartAfterMarker(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	data := "banner\npreamble\n=== SESSION START ===\nfirst\nsecond\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(tmp, "missing.log")
+	if err := os.WriteFile(missing, []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	isStart := func(line string) bool { return strings.Contains(line, "SESSION START") }
+	tests := []struct {
+		name      string
+		path      string
+		inclusive bool
+		fallback  MarkerFallback
+		want      []string
+	}{
+		{"exclusive", path, false, MarkerSkipAll, []string{"first", "second"}},
+		{"inclusive", path, true, MarkerSkipAll, []string{"=== SESSION START ===", "first", "second"}},
+		{"missing skip all", missing, false, MarkerSkipAll, nil},
+		{"missing deliver all", missing, false, MarkerDeliverAll, []string{"one", "two"}},
+	}
+	for _, tt := range tests {
+		for _, mmap := range []bool{false, true} {
+			lines, err := ReadAll(ctx, tt.path, WithMmap(mmap), WithStartAfterMarker(isStart, tt.inclusive, tt.fallback))
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			var got []string
+			for _, l := range lines {
+				got = append(got, l.Text)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("%s (mmap=%v): got %q, want %q", tt.name, mmap, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestFollowStartAfterMarkerNotFound(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("old 1\nold 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// The marker is only searched for in the initial content, so a
+	// later line that matches it is delivered like any other.
+	tailer, err := Follow(ctx, path,
+		WithFromStart(true),
+		WithPollInterval(10*time.Millisecond),
+		WithStartAfterMarker(func(line string) bool { return line == "START" }, false, MarkerSkipAll),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-tailer.CaughtUp()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("START\nnew\n")
+	f.Close()
+
+	for _, want := range []string{"START", "new"} {
+		select {
+		case line := <-tailer.Lines():
+			if line.Text != want {
+				t.Errorf("got %q, want %q", line.Text, want)
+			}
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for %q", want)
+		}
+	}
+
+	cancel()
+	<-tailer.Done()
+}
@@ -0,0 +1,107 @@
+package tailf
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"runtime/debug"
+)
+
+// replayMapped delivers the complete lines between the current position
+// and the end of the file straight from a memory mapping, then leaves
+// the file positioned after the last complete line for normal reading.
+// It returns false if ctx was cancelled. Files that cannot be mapped are
+// left untouched.
+func (s *tailState) replayMapped(ctx context.Context) (bool, error) {
+	framer, ok := s.o.framer.(lineFramer)
+	if !ok || framer.delims != "" || s.gz != nil || s.o.writerMmap || s.scan.header > 0 {
+		return true, nil
+	}
+
+	pos, err := s.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return false, s.fail("seek", err)
+	}
+	info, err := s.file.Stat()
+	if err != nil {
+		return false, s.fail("stat", err)
+	}
+	size := info.Size()
+	if size <= pos || int64(int(size)) != size {
+		return true, nil
+	}
+
+	data, unmap, ok := mmapFile(s.file, int(size))
+	if !ok {
+		return true, nil
+	}
+	defer unmap()
+
+	epoch := s.epoch
+	s.mapping = true
+	defer func() { s.mapping = false }()
+	off, ok, err := s.scanMapped(ctx, framer, data, int(pos), epoch)
+	if !ok {
+		return false, err
+	}
+
+	// A command such as JumpToEnd has already repositioned the file.
+	if s.epoch != epoch {
+		return true, nil
+	}
+	if _, err := s.file.Seek(int64(off), io.SeekStart); err != nil {
+		return false, s.fail("seek", err)
+	}
+	s.scan.r.Reset(fileSource(s.file, &s.o))
+	return true, nil
+}
+
+// scanMapped delivers the complete lines of data from off on, and
+// returns the offset after the last one. If the file is truncated
+// meanwhile, touching a page past its new end faults; the fault is
+// recovered from and the offset of the line being framed returned, so
+// that ordinary reads take over and notice the truncation. ok is false
+// if ctx was cancelled or delivery failed, with the fatal error, if any.
+func (s *tailState) scanMapped(ctx context.Context, framer lineFramer, data []byte, off, epoch int) (end int, ok bool, err error) {
+	defer debug.SetPanicOnFault(debug.SetPanicOnFault(true))
+	defer func() {
+		if r := recover(); r != nil {
+			if _, fault := r.(interface{ Addr() uintptr }); !fault {
+				panic(r)
+			}
+			end, ok, err = off, true, nil
+		}
+	}()
+
+	for off < len(data) && s.epoch == epoch {
+		i := bytes.IndexByte(data[off:], '\n')
+		if i < 0 {
+			break
+		}
+		line := data[off : off+i+1]
+		payload, term := framer.split(line)
+		payload = s.scan.stripPrefix(payload)
+		if len(payload) == 0 || s.scan.skipping() {
+			off += len(line)
+			s.mapOff = int64(off)
+			s.t.recordRead(len(line))
+			s.scan.torn = false
+			continue
+		}
+		l := s.scan.line(payload, term)
+		off += len(line)
+		s.mapOff = int64(off)
+		s.t.recordRead(len(line))
+		l.Partial, s.scan.torn = s.scan.torn, false
+		if s.beforeMarker(l) {
+			continue
+		}
+		s.inFlight = len(line)
+		ok := s.deliver(ctx, l)
+		s.inFlight = 0
+		if !ok {
+			return off, false, s.fatal
+		}
+	}
+	return off, true, nil
+}
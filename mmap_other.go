@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package tailf
+
+import "os"
+
+// mmapFile is not supported on this platform; the file is read normally.
+func mmapFile(_ *os.File, _ int) ([]byte, func(), bool) {
+	return nil, nil, false
+}
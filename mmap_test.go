@@ -0,0 +1,148 @@
+package tailf
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFollowMmap(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("one\r\n\ntwo\nthr"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFromStart(true), WithMmap(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The mapped backlog ends in a partial line, which must be completed
+	// by the normal read path once the rest arrives.
+	time.Sleep(150 * time.Millisecond)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("ee\nfour\n")
+	f.Close()
+
+	expected := []string{"one", "two", "three", "four"}
+	for i, want := range expected {
+		select {
+		case line := <-tailer.Lines():
+			if line.Text != want {
+				t.Errorf("line %d: got %q, want %q", i, line.Text, want)
+			}
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for line %d", i)
+		}
+	}
+
+	if got, want := tailer.Stats().BytesRead, int64(len("one\r\n\ntwo\nthree\nfour\n")); got != want {
+		t.Errorf("BytesRead = %d, want %d", got, want)
+	}
+
+	cancel()
+	<-tailer.Done()
+}
+
+func TestFollowMmapTruncatedDuringReplay(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	// Far more lines than the channel buffers, so the replay is blocked
+	// in the middle of the mapping when the file is truncated.
+	line := fmt.Sprintf("%0127d\n", 0)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := bufio.NewWriter(f)
+	for i := 0; i < 100000; i++ {
+		w.WriteString(line)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFromStart(true), WithMmap(true), WithPollInterval(20*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-tailer.Lines():
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the first line")
+	}
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	appendLine(t, path, "after")
+
+	for {
+		select {
+		case l := <-tailer.Lines():
+			if l.Text == "after" {
+				cancel()
+				<-tailer.Done()
+				return
+			}
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for the line written after the truncation")
+		}
+	}
+}
+
+// BenchmarkReplay measures how fast a large backlog is replayed with
+// and without WithMmap. Raise benchReplaySize to reproduce results on
+// multi-gigabyte files.
+func BenchmarkReplay(b *testing.B) {
+	const benchReplaySize = 64 << 20
+
+	path := filepath.Join(b.TempDir(), "bench.log")
+	f, err := os.Create(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	w := bufio.NewWriter(f)
+	var lines int
+	for written := 0; written < benchReplaySize; lines++ {
+		n, _ := fmt.Fprintf(w, "2024-01-01T00:00:00Z INFO request %d served in 12ms\n", lines)
+		written += n
+	}
+	w.Flush()
+	f.Close()
+
+	for _, mmap := range []bool{false, true} {
+		b.Run(fmt.Sprintf("mmap=%v", mmap), func(b *testing.B) {
+			b.SetBytes(benchReplaySize)
+			for i := 0; i < b.N; i++ {
+				ctx, cancel := context.WithCancel(context.Background())
+				tailer, err := Follow(ctx, path, WithFromStart(true), WithMmap(mmap))
+				if err != nil {
+					b.Fatal(err)
+				}
+				for n := 0; n < lines; n++ {
+					<-tailer.Lines()
+				}
+				cancel()
+				<-tailer.Done()
+			}
+		})
+	}
+}
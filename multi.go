@@ -0,0 +1,121 @@
+package tailf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// MultiTailer follows several files at once and merges their lines
+// into a single channel. Create one with [FollowMulti] or [FollowGlob].
+type MultiTailer struct {
+	lines chan Line
+	done  chan struct{}
+	wg    sync.WaitGroup // the forwarding goroutines, and any rescan
+
+	mu      sync.Mutex
+	tailers []*Tailer
+}
+
+// FollowMulti follows each of paths as [Follow] would, with the same
+// options, and delivers all their lines on one channel, with
+// [Line].Source set to the path each line was read from.
+//
+// Lines of different files interleave in no particular order, but the
+// lines of each file arrive in file order: every file is forwarded by a
+// single goroutine, which sends one line to the merged channel before
+// receiving the next. Filtering the merged stream by Source therefore
+// reproduces each file's own sequence.
+//
+// If any path cannot be opened, the files already opened are released
+// and the error is returned. Tailing stops when ctx is cancelled.
+func FollowMulti(ctx context.Context, paths []string, opts ...Option) (*MultiTailer, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	m := &MultiTailer{
+		lines: make(chan Line, lineBuffer),
+		done:  make(chan struct{}),
+	}
+	for _, path := range paths {
+		t, err := Follow(ctx, path, opts...)
+		if err != nil {
+			cancel()
+			for _, t := range m.tailers {
+				<-t.Done()
+			}
+			return nil, fmt.Errorf("tailf: %s: %w", path, errors.Unwrap(err))
+		}
+		m.tailers = append(m.tailers, t)
+	}
+
+	for i, t := range m.tailers {
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			m.forward(ctx, paths[i], t)
+		}()
+	}
+	go m.finish(cancel)
+	return m, nil
+}
+
+// add starts forwarding the lines of t, read from path. It must be
+// called before [MultiTailer.finish] can see the wait group at zero.
+func (m *MultiTailer) add(ctx context.Context, path string, t *Tailer) {
+	m.mu.Lock()
+	m.tailers = append(m.tailers, t)
+	m.mu.Unlock()
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.forward(ctx, path, t)
+	}()
+}
+
+// finish waits for every file to stop being forwarded, then stops the
+// tailers and closes the merged channel.
+func (m *MultiTailer) finish(cancel context.CancelFunc) {
+	m.wg.Wait()
+	cancel()
+	close(m.lines)
+	for _, t := range m.tailers {
+		<-t.Done()
+	}
+	close(m.done)
+}
+
+// forward passes the lines of t, read from path, to the merged channel
+// in order, until t stops or ctx is cancelled.
+func (m *MultiTailer) forward(ctx context.Context, path string, t *Tailer) {
+	for l := range t.Lines() {
+		l.Source = path
+		select {
+		case m.lines <- l:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Lines returns the channel on which the lines of all files are
+// delivered. It is closed once every file has stopped being followed.
+func (m *MultiTailer) Lines() <-chan Line {
+	return m.lines
+}
+
+// Done returns a channel that is closed when every file has stopped
+// being followed and all resources have been released.
+func (m *MultiTailer) Done() <-chan struct{} {
+	return m.done
+}
+
+// Err returns the errors that stopped the individual tailers, joined
+// with [errors.Join], or nil if they were stopped by context
+// cancellation. Only meaningful after [MultiTailer.Done] is closed.
+func (m *MultiTailer) Err() error {
+	var errs []error
+	for _, t := range m.tailers {
+		errs = append(errs, t.Err())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,91 @@
+package tailf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFollowMultiPerSourceOrder(t *testing.T) {
+	tmp := t.TempDir()
+	const files, perFile = 4, 500
+
+	var paths []string
+	for i := 0; i < files; i++ {
+		path := filepath.Join(tmp, fmt.Sprintf("f%d.log", i))
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	m, err := FollowMulti(ctx, paths, WithPollInterval(5*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for _, path := range paths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer f.Close()
+			for n := 0; n < perFile; n++ {
+				fmt.Fprintf(f, "%d\n", n)
+			}
+		}()
+	}
+	wg.Wait()
+
+	next := make(map[string]int)
+	for received := 0; received < files*perFile; received++ {
+		select {
+		case line := <-m.Lines():
+			want := fmt.Sprint(next[line.Source])
+			if line.Text != want {
+				t.Fatalf("%s: got %q, want %q", line.Source, line.Text, want)
+			}
+			next[line.Source]++
+		case <-ctx.Done():
+			t.Fatalf("timed out after %d lines: %v", received, next)
+		}
+	}
+	for _, path := range paths {
+		if next[path] != perFile {
+			t.Errorf("%s: got %d lines, want %d", path, next[path], perFile)
+		}
+	}
+
+	cancel()
+	<-m.Done()
+	if _, ok := <-m.Lines(); ok {
+		t.Error("Lines still open after Done")
+	}
+	if err := m.Err(); err != nil {
+		t.Errorf("Err = %v", err)
+	}
+}
+
+func TestFollowMultiMissing(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "present.log")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := FollowMulti(context.Background(), []string{path, filepath.Join(tmp, "missing.log")})
+	if err == nil {
+		t.Fatal("FollowMulti with a missing path succeeded")
+	}
+}
@@ -0,0 +1,40 @@
+package tailf
+
+import "context"
+
+// mergeNotify fans the notification channels first and more into a
+// single channel. Signals are coalesced: while one is pending, further
+// ones are dropped, since a single read catches up with all of them.
+// The forwarding goroutines exit when ctx is done or stop is closed, or
+// when their source channel is closed.
+func mergeNotify(ctx context.Context, stop <-chan struct{}, first <-chan struct{}, more []<-chan struct{}) <-chan struct{} {
+	out := make(chan struct{}, 1)
+	forward := func(ch <-chan struct{}) {
+		for {
+			select {
+			case _, ok := <-ch:
+				if !ok {
+					return
+				}
+				select {
+				case out <- struct{}{}:
+				default:
+				}
+			case <-ctx.Done():
+				return
+			case <-stop:
+				return
+			}
+		}
+	}
+
+	if first != nil {
+		go forward(first)
+	}
+	for _, ch := range more {
+		if ch != nil {
+			go forward(ch)
+		}
+	}
+	return out
+}
@@ -0,0 +1,154 @@
+package tailf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestFollowNotifyChannels(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte(""), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	baseline := runtime.NumGoroutine()
+
+	watcher := make(chan struct{}, 1)
+	kick := make(chan struct{}, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// Use a long poll interval so only the notify channels trigger reads.
+	tailer, err := Follow(ctx, path,
+		WithFromStart(true),
+		WithPollInterval(10*time.Second),
+		WithNotify(watcher),
+		WithNotifyChannels(kick),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-tailer.CaughtUp()
+
+	for i, ch := range []chan struct{}{watcher, kick} {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteString("signal\n")
+		f.Close()
+
+		ch <- struct{}{}
+
+		select {
+		case line := <-tailer.Lines():
+			if line.Text != "signal" {
+				t.Errorf("got %q, want %q", line.Text, "signal")
+			}
+		case <-ctx.Done():
+			t.Fatalf("timed out — notify channel %d did not trigger read", i)
+		}
+	}
+
+	cancel()
+	<-tailer.Done()
+
+	// The merge goroutines must not outlive the tailer.
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > baseline && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > baseline {
+		t.Errorf("goroutines: got %d, want at most %d", n, baseline)
+	}
+}
+
+func TestNotifyCoalesce(t *testing.T) {
+	for _, tt := range []struct {
+		window   time.Duration
+		min, max int
+	}{
+		{0, 100, 100},
+		{50 * time.Millisecond, 2, 2}, // the first notification, then the window's end
+	} {
+		notify := make(chan struct{}, 100)
+		for i := 0; i < 100; i++ {
+			notify <- struct{}{}
+		}
+
+		o := defaults()
+		o.notify = notify
+		o.pollInterval = time.Hour
+		o.notifyCoalesce = tt.window
+		s := &tailState{t: &Tailer{}, o: o}
+
+		// Count the read cycles the burst causes within twice the window.
+		ctx, cancel := context.WithTimeout(context.Background(), 2*tt.window+20*time.Millisecond)
+		wakeups := 0
+		for {
+			s.waitForData(ctx)
+			if ctx.Err() != nil {
+				break
+			}
+			wakeups++
+		}
+		cancel()
+
+		if wakeups < tt.min || wakeups > tt.max {
+			t.Errorf("window %v: %d wakeups, want %d to %d", tt.window, wakeups, tt.min, tt.max)
+		}
+	}
+}
+
+func TestReadTimeoutCapsFallbackPoll(t *testing.T) {
+	if !RotationDetectionSupported() {
+		t.Skip("rotation detection not supported")
+	}
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// The notification never fires and the fallback poll is far off;
+	// only the read timeout brings the tailer back to check the path.
+	tailer, err := Follow(ctx, path,
+		WithNotify(make(chan struct{})),
+		WithPollInterval(time.Hour),
+		WithReadTimeout(20*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("after rotation\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case line := <-tailer.Lines():
+		if line.Text != "after rotation" {
+			t.Errorf("got %q, want %q", line.Text, "after rotation")
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the rotated file")
+	}
+
+	cancel()
+	<-tailer.Done()
+}
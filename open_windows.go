@@ -0,0 +1,30 @@
+//go:build windows
+
+package tailf
+
+import (
+	"os"
+	"syscall"
+)
+
+// openShared opens path for reading. Unlike [os.Open], it lets other
+// processes rename and delete the file while it is open, so that the
+// tailer does not stop a writer from rotating its log.
+func openShared(path string) (*os.File, error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: path, Err: err}
+	}
+	h, err := syscall.CreateFile(name,
+		syscall.GENERIC_READ,
+		syscall.FILE_SHARE_READ|syscall.FILE_SHARE_WRITE|syscall.FILE_SHARE_DELETE,
+		nil,
+		syscall.OPEN_EXISTING,
+		// Needed to open a directory, which openFile then rejects.
+		syscall.FILE_ATTRIBUTE_NORMAL|syscall.FILE_FLAG_BACKUP_SEMANTICS,
+		0)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: path, Err: err}
+	}
+	return os.NewFile(uintptr(h), path), nil
+}
@@ -1,6 +1,12 @@
 package tailf
 
-import "time"
+import (
+	"io"
+	"math"
+	"os"
+	"slices"
+	"time"
+)
 
 // Option configures a Tailer.
 type Option func(*options)
@@ -9,13 +15,158 @@ type options struct {
 	fromStart    bool
 	pollInterval time.Duration
 	notify       <-chan struct{}
+	notifyChans  []<-chan struct{}
 	bufSize      int
+
+	onCaughtUp    func()
+	inBandMarkers bool
+
+	onEvent    func(Event)
+	stuckPolls int
+
+	framer     Framer
+	strictCRLF bool
+
+	throughputWindow time.Duration
+
+	fallbackPaths []string
+
+	filter        func(Line) bool
+	contextBefore int
+	contextAfter  int
+
+	mmap bool
+
+	levelParser LevelParser
+
+	rotationCooldown time.Duration
+
+	startPercent float64
+	initialBytes int64
+
+	transform func(string) string
+	tabWidth  int
+
+	memoryBudget int64
+	overflow     OverflowPolicy
+
+	restartOnShrink bool
+
+	spill    bool
+	spillDir string
+	spillMax int64
+
+	stopAtEOF           bool
+	requireFinalNewline bool
+
+	restartDelay time.Duration
+	maxRestarts  int
+
+	manualPoll <-chan struct{}
+
+	gzip bool
+
+	detectRotation bool
+
+	tee      io.Writer
+	teeFatal bool
+
+	readDeadline time.Duration
+
+	notifyCoalesce time.Duration
+
+	replaySignal os.Signal
+
+	stripPrefix int
+
+	followByName bool
+
+	pathTruncationCheck bool
+
+	rotationIndex func(path string) int
+
+	lineTimeout time.Duration
+
+	identity func(info os.FileInfo, path string) (string, error)
+
+	archivePattern string
+
+	caughtUpStable time.Duration
+
+	decorate func(*Line)
+
+	delimiters string
+
+	readTimeout time.Duration
+
+	startMarker     func(line string) bool
+	markerInclusive bool
+	markerFallback  MarkerFallback
+
+	resumeToken    Token
+	resumeFallback ResumeFallback
+
+	dedupRecent int
+
+	followSymlink bool
+
+	reorderSeq    func(line string) (int64, bool)
+	reorderWindow int
+
+	scheduler *Scheduler
+
+	skipHeader int64
+	onHeader   func(header []byte)
+
+	progressEvery time.Duration
+	progress      func(bytesRead, totalBytes, linesRead int64)
+
+	prefixAllow *prefixSet
+	prefixDeny  *prefixSet
+
+	alignToLine bool
+
+	lifecycle chan<- LifecycleEvent
+
+	aggregator Aggregator
+
+	compressedResume func(path string) string
+
+	maxFileSize int64
+
+	writerMmap bool
+
+	debounce time.Duration
+
+	pressure chan<- float64
+
+	fromStartOnce bool
+
+	rotationDrainTimeout time.Duration
+
+	textDecoding bool
+
+	appendOnly bool
+
+	filterChain *FilterChain
+
+	rescanInterval time.Duration
 }
 
 func defaults() options {
 	return options{
 		pollInterval: 100 * time.Millisecond,
 		bufSize:      4096,
+		stuckPolls:   5,
+		startPercent: -1,
+
+		throughputWindow: 5 * time.Second,
+		detectRotation:   true,
+		followSymlink:    true,
+		alignToLine:      true,
+
+		rotationDrainTimeout: 2 * time.Second,
+		rescanInterval:       time.Second,
 	}
 }
 
@@ -29,14 +180,21 @@ func WithFromStart(b bool) Option {
 	}
 }
 
+// minPollInterval is the shortest poll interval, which keeps a tailer
+// at the end of the file from spinning.
+const minPollInterval = time.Millisecond
+
 /*
 WithPollInterval sets the interval between EOF poll cycles.
 Default is 100ms. Ignored when a notify channel is provided,
-but still used as a fallback timeout.
+but still used as a fallback timeout. Intervals below 1ms,
+including zero and negative ones, are raised to 1ms, so a
+tailer waiting for data never busy-spins; use [WithManualPoll]
+to read only on demand.
 */
 func WithPollInterval(d time.Duration) Option {
 	return func(o *options) {
-		o.pollInterval = d
+		o.pollInterval = max(d, minPollInterval)
 	}
 }
 
@@ -66,3 +224,1089 @@ func WithBufSize(n int) Option {
 		o.bufSize = n
 	}
 }
+
+/*
+WithCaughtUpMarker registers a callback invoked once, from the tailing
+goroutine, when the tailer reaches the end of the existing content for
+the first time and begins following live. The callback must not block.
+*/
+func WithCaughtUpMarker(fn func()) Option {
+	return func(o *options) {
+		o.onCaughtUp = fn
+	}
+}
+
+/*
+WithInBandMarkers configures whether synthetic marker lines are injected
+into the [Tailer.Lines] stream. When enabled, a Line with Marker set to
+[CaughtUp] and empty Text is delivered between the initial backlog and
+the first live line, so consumers ranging over Lines can tell history
+from live data without a separate select. The marker is delivered even
+under [OverflowDrop], waiting for room in the channel if need be.
+*/
+func WithInBandMarkers(b bool) Option {
+	return func(o *options) {
+		o.inBandMarkers = b
+	}
+}
+
+/*
+WithEventHandler registers a callback invoked from the tailing goroutine
+whenever the tailer detects a truncation, rotation or reopen. The
+callback must not block.
+*/
+func WithEventHandler(fn func(Event)) Option {
+	return func(o *options) {
+		o.onEvent = fn
+	}
+}
+
+/*
+WithStuckReopen sets how many consecutive EOF polls the tailer tolerates
+while the file at the path is larger than the current read position
+before it concludes the handle is stuck and reopens the path at the
+same offset (or from the start if that offset is no longer valid).
+Default is 5. A value of zero or less disables the check.
+*/
+func WithStuckReopen(polls int) Option {
+	return func(o *options) {
+		o.stuckPolls = polls
+	}
+}
+
+/*
+WithFramer replaces the default newline framing with a custom [Framer],
+allowing the tailer to follow streams of length-prefixed or otherwise
+non-delimited records. Each record's payload is delivered as the Text
+of a Line; empty payloads are skipped.
+*/
+func WithFramer(f Framer) Option {
+	return func(o *options) {
+		o.framer = f
+	}
+}
+
+/*
+WithThroughputWindow sets the time constant of the exponentially
+weighted moving average behind Stats().BytesPerSec. Shorter windows
+react faster to bursts; longer windows give a smoother figure.
+Default is 5s.
+*/
+func WithThroughputWindow(d time.Duration) Option {
+	return func(o *options) {
+		o.throughputWindow = d
+	}
+}
+
+/*
+WithStrictCRLF configures how line terminators are trimmed. By default
+every trailing '\r' and '\n' is stripped from a line. In strict mode
+exactly one "\r\n" or "\n" is removed, so carriage returns that are
+part of the line's content are preserved: "x\r\r\n" yields "x\r".
+Ignored when a custom [Framer] is set.
+*/
+func WithStrictCRLF(b bool) Option {
+	return func(o *options) {
+		o.strictCRLF = b
+	}
+}
+
+/*
+WithFallbackPaths lists alternative locations of the same log, tried in
+order when the primary path cannot be opened at start, or when the path
+being followed disappears. Switching paths is treated like a rotation:
+the new file is read from the start and an [EventSwitched] event is
+emitted.
+
+This is for failover, not merging: only one path is followed at a time.
+*/
+func WithFallbackPaths(paths ...string) Option {
+	return func(o *options) {
+		o.fallbackPaths = append(o.fallbackPaths, paths...)
+	}
+}
+
+/*
+WithFilter sets a predicate deciding which lines are delivered. Lines
+for which fn returns false are dropped, unless they are context lines
+of a match (see [WithContextLines]). fn runs in the tailing goroutine
+and should be cheap.
+*/
+func WithFilter(fn func(Line) bool) Option {
+	return func(o *options) {
+		o.filter = fn
+	}
+}
+
+/*
+WithContextLines delivers up to before lines preceding and after lines
+following each line accepted by [WithFilter], like grep -B and -A.
+Overlapping context windows are merged, so no line is delivered twice.
+Has no effect without a filter.
+*/
+func WithContextLines(before, after int) Option {
+	return func(o *options) {
+		o.contextBefore = before
+		o.contextAfter = after
+	}
+}
+
+/*
+WithMmap configures whether the existing content of the file is read
+through a read-only memory mapping. Splitting lines directly in the
+mapped bytes avoids a copy per line and speeds up replaying large
+backlogs with [WithFromStart]. Once the backlog is consumed, the tailer
+follows new data with ordinary reads. If the file is truncated while the
+backlog is being replayed, the tailer falls back to ordinary reads from
+the first line it had not yet framed, and handles the truncation as
+usual.
+
+Only the default newline framing is accelerated. On platforms without
+mmap support, or when the file cannot be mapped, the option is ignored
+and the file is read normally.
+*/
+func WithMmap(b bool) Option {
+	return func(o *options) {
+		o.mmap = b
+	}
+}
+
+/*
+WithLevelParser sets a parser that extracts a severity from each line's
+text into [Line.Level]. It runs in the tailing goroutine before
+[WithFilter], so filters can drop lines by level at the source, and
+should be cheap. See [SyslogLevel], [LogfmtLevel] and [JSONLevel] for
+ready-made parsers.
+*/
+func WithLevelParser(fn LevelParser) Option {
+	return func(o *options) {
+		o.levelParser = fn
+	}
+}
+
+/*
+WithRotationCooldown sets the minimum time after a rotation before the
+tailer will act on another one. A file that was just rotated in is
+always given at least one poll cycle; the cooldown extends that window
+so a freshly created, still empty file has time to receive data when
+rotations happen in quick succession. Default is zero.
+*/
+func WithRotationCooldown(d time.Duration) Option {
+	return func(o *options) {
+		o.rotationCooldown = d
+	}
+}
+
+/*
+WithStartAtPercent starts tailing at the fraction p of the file's
+current size, from 0.0 (the start) to 1.0 (the end), which is a cheap
+way to sample the recent part of a large file. Values outside that
+range are clamped. If the position falls inside a line, the rest of
+that line is skipped, so the first delivered line is complete, unless
+[WithAlignToLine] is off. Overrides [WithFromStart].
+*/
+func WithStartAtPercent(p float64) Option {
+	return func(o *options) {
+		o.startPercent = max(p, 0)
+	}
+}
+
+/*
+WithTransform sets a function that rewrites the text of each line before
+it is delivered. It runs in the tailing goroutine after tab expansion
+(see [WithExpandTabs]) and before [WithLevelParser] and [WithFilter],
+which therefore see the transformed text.
+*/
+func WithTransform(fn func(string) string) Option {
+	return func(o *options) {
+		o.transform = fn
+	}
+}
+
+/*
+WithExpandTabs replaces each tab in a line's text with spaces up to the
+next tab stop, with stops every width columns, so lines align in a
+viewer. Columns count runes from the start of the line. Tabs are
+expanded before [WithTransform] runs. A width of zero or less disables
+expansion, which is the default.
+*/
+func WithExpandTabs(width int) Option {
+	return func(o *options) {
+		o.tabWidth = width
+	}
+}
+
+/*
+WithNotifyChannels adds notification channels that are merged with the
+[WithNotify] channel, if any, so that several sources, such as a file
+watcher and a manual trigger, can each wake the tailer. A signal on any
+of them triggers an immediate read. The channels are merged by helper
+goroutines that exit when the tailer stops. [OpenPull], which never
+starts goroutines, only uses the WithNotify channel.
+*/
+func WithNotifyChannels(chs ...<-chan struct{}) Option {
+	return func(o *options) {
+		o.notifyChans = append(o.notifyChans, chs...)
+	}
+}
+
+/*
+WithMemoryBudget bounds the approximate memory, in bytes of line text,
+that the tailer holds for lines not yet received: lines waiting in the
+Lines channel, lines held back as filter context (see
+[WithContextLines]) and an incomplete trailing line. When delivering a
+line would exceed the budget, the [WithOverflowPolicy] policy applies.
+A line is always delivered when nothing else is buffered, so a single
+line larger than the budget cannot stall the tailer. Current usage is
+reported by [Stats].BufferedBytes. Zero or less disables the budget,
+which is the default. [OpenPull] ignores the budget.
+*/
+func WithMemoryBudget(bytes int64) Option {
+	return func(o *options) {
+		o.memoryBudget = bytes
+	}
+}
+
+/*
+WithOverflowPolicy sets what happens when a line cannot be delivered
+because the Lines channel is full or the [WithMemoryBudget] budget is
+exhausted. [OverflowBlock], the default, waits for the receiver to catch
+up, so no line is lost but reading falls behind. [OverflowDrop] discards
+the line and counts it in [Stats].Dropped. Marker lines of
+[WithInBandMarkers] are never dropped; they wait for room as under
+OverflowBlock.
+*/
+func WithOverflowPolicy(p OverflowPolicy) Option {
+	return func(o *options) {
+		o.overflow = p
+	}
+}
+
+/*
+WithRestartOnShrink makes the tailer re-read the file from the start
+whenever its size drops below the size seen at the previous poll, even
+if the current position is still within the new size. This suits status
+and summary files that are rewritten in place rather than appended to.
+Each restart emits an [EventTruncated] event. A file replaced by a new
+one at the path is always read from the start. Default is false.
+*/
+func WithRestartOnShrink(enabled bool) Option {
+	return func(o *options) {
+		o.restartOnShrink = enabled
+	}
+}
+
+/*
+WithSpillToDisk keeps lines that arrive while the Lines channel is full
+in a temporary file in dir, instead of waiting for the receiver, and
+delivers them in order once it catches up. This lets a slow receiver
+fall far behind without the tailer holding the lines in memory or
+falling behind the file. The spill file holds at most maxBytes bytes, or
+is unbounded if maxBytes is zero or less; once it is full, the
+[WithOverflowPolicy] policy applies. An empty dir uses [os.TempDir].
+The file is removed when the tailer stops, discarding any lines still
+queued in it. [Stats] reports the lines and bytes currently spilled.
+Lines are written to the file with encoding/json, so the values of a
+spilled line's [Line].Meta come back with the types JSON decodes to,
+such as float64 for an int; a line whose Meta cannot be encoded is not
+spilled, as if the file were full. [OpenPull] ignores this option.
+*/
+func WithSpillToDisk(dir string, maxBytes int64) Option {
+	return func(o *options) {
+		o.spill = true
+		o.spillDir = dir
+		o.spillMax = maxBytes
+	}
+}
+
+/*
+WithStopAtEOF makes the tailer stop when it first reaches the end of the
+file instead of waiting for more data, closing the Lines channel with a
+nil [Tailer.Err]. An incomplete last line is delivered with
+[Line].Partial set unless [WithRequireFinalNewline] is enabled. See
+also [ReadAll]. Default is false.
+*/
+func WithStopAtEOF(enabled bool) Option {
+	return func(o *options) {
+		o.stopAtEOF = enabled
+	}
+}
+
+/*
+WithRequireFinalNewline suppresses the incomplete last line that
+[WithStopAtEOF] would otherwise deliver, for callers that only want
+complete lines. A withheld line is reported as [ErrTrailingPartial] by
+[Tailer.Err]. Default is false.
+*/
+func WithRequireFinalNewline(enabled bool) Option {
+	return func(o *options) {
+		o.requireFinalNewline = enabled
+	}
+}
+
+/*
+WithSupervise makes the tailer recover from fatal errors, such as a
+failed read, instead of stopping. After an error it waits restartDelay,
+then reopens the path at the offset where the error occurred, as
+reported by [TailError].Offset, or at the end of the file if that offset
+is no longer valid, and carries on. A record whose reading failed midway
+is read again from its start. Each restart emits an [EventReopened]
+event and counts in [Stats].Reopens. After maxRestarts restarts, the
+next fatal error stops the tailer and is reported by [Tailer.Err] as
+usual. Default is no restarts. [OpenPull] ignores this option.
+*/
+func WithSupervise(restartDelay time.Duration, maxRestarts int) Option {
+	return func(o *options) {
+		o.restartDelay = restartDelay
+		o.maxRestarts = maxRestarts
+	}
+}
+
+/*
+WithManualPoll makes the tailer check for new data only when a value is
+received from trigger, with no poll timer at all, so that tests and
+benchmarks control exactly when reads happen. It overrides
+[WithPollInterval] and [WithNotify]. Each receive from trigger leads to
+one round of reading up to the end of the file and checking for
+truncation and rotation. It is meant for testing; a tailer whose trigger
+never fires never sees new data.
+*/
+func WithManualPoll(trigger <-chan struct{}) Option {
+	return func(o *options) {
+		o.manualPoll = trigger
+	}
+}
+
+/*
+WithGzipInput treats the file as a growing gzip stream made of
+concatenated members, as written by a process that compresses each
+batch of output separately and appends it. Each member is decompressed
+once it has been written completely; a member that is still incomplete
+is retried when more data arrives, so lines are delivered as their
+members are finished. Lines may span members.
+
+Only append-only streams are supported. Tailing starts at the start of
+the file or, by default, at its current end, which must be a member
+boundary; [WithStartAtPercent] and [WithInitialBytes] are ignored.
+Stuck handle detection is disabled, and a rotated file is not drained
+before switching to its replacement. Default is false.
+*/
+func WithGzipInput(enabled bool) Option {
+	return func(o *options) {
+		o.gzip = enabled
+	}
+}
+
+/*
+WithRotationDetection controls whether the tailer checks the path for a
+replaced file at each poll. Disabling it saves a stat of the path per
+poll for files that are never rotated, and avoids spurious switches when
+the path is unstable. Without it, truncation is still handled, but
+rotation, stuck handles and [WithFallbackPaths] failover are not
+detected. Default is true.
+*/
+func WithRotationDetection(enabled bool) Option {
+	return func(o *options) {
+		o.detectRotation = enabled
+	}
+}
+
+/*
+WithTee copies each line that passes the filter to w, as its text
+followed by a newline, once the line has been accepted for delivery:
+handed to the Lines channel, or queued by [WithSpillToDisk]. Lines
+discarded under [OverflowDrop] are not written, so w receives exactly
+the lines delivered. The write happens in the tailing goroutine, so a
+slow writer slows tailing down. If a write fails and fatal is set, the
+tailer stops with a [*TailError] whose Op is "tee"; otherwise the error
+is ignored and counted in [Stats].TeeErrors.
+*/
+func WithTee(w io.Writer, fatal bool) Option {
+	return func(o *options) {
+		o.tee = w
+		o.teeFatal = fatal
+	}
+}
+
+/*
+WithReadDeadline bounds how long a single read may block, so that a
+read hanging on an overloaded disk or an idle pipe is interrupted, the
+context checked and the read retried. It relies on
+[os.File.SetReadDeadline], which only works for files that support
+deadlines, such as pipes and FIFOs; for regular files, whose reads do
+not block indefinitely on most systems, it has no effect. Zero, the
+default, disables the deadline.
+*/
+func WithReadDeadline(d time.Duration) Option {
+	return func(o *options) {
+		o.readDeadline = d
+	}
+}
+
+/*
+WithNotifyCoalesce treats notifications that arrive within window of the
+one that last woke the tailer as part of the same burst. Instead of a
+read per notification, the tailer reads once more when the window ends,
+which cuts down on reads and stats under a chatty notification source,
+such as a watcher that fires on every write, at the cost of up to window
+of extra latency during a burst. Zero, the default, disables coalescing.
+*/
+func WithNotifyCoalesce(window time.Duration) Option {
+	return func(o *options) {
+		o.notifyCoalesce = window
+	}
+}
+
+/*
+WithReplaySignal makes the tailer re-read the current file from the
+start whenever the process receives sig, such as [syscall.SIGUSR1],
+emitting an [EventReopened] event. The handle is kept: unlike a rotation
+or reopen, the path is not resolved again. Every line already read is
+delivered a second time, so consumers that are not idempotent will see
+duplicates. The signal handler is removed when the tailer stops. It has
+no effect on a [PullTailer]. Default is nil.
+*/
+func WithReplaySignal(sig os.Signal) Option {
+	return func(o *options) {
+		o.replaySignal = sig
+	}
+}
+
+/*
+WithStripPrefix removes the first n bytes of each line, after the line
+terminator has been stripped and before tab expansion and
+[WithTransform], for fixed-width formats that begin with a framing or
+sequence column. A line of n bytes or fewer becomes empty and, like
+any empty line, is skipped. Default is 0, which keeps lines whole.
+*/
+func WithStripPrefix(n int) Option {
+	return func(o *options) {
+		o.stripPrefix = n
+	}
+}
+
+/*
+WithFollowByName follows the path rather than the file it names, like
+"tail -F". It enables the following, and nothing else:
+
+  - If the path cannot be opened when tailing starts, [Follow] does not
+    fail but waits, retrying every poll interval, and reads the file
+    from the start once it can be opened. Tailer methods that need the
+    file return a [*TailError] until then. [OpenPull] still requires
+    the file to be openable.
+  - Rotation detection, as by WithRotationDetection(true): each poll at
+    the end of the file stats the path, and a different inode there
+    means a new file, which is read from the start. A deleted path keeps
+    the old handle until a file is created under the name again, and a
+    new file that cannot be opened yet is retried on the next poll.
+  - Supervision, as by WithSupervise(time.Second, math.MaxInt): after a
+    fatal error the path is reopened, indefinitely.
+
+Options given after it, such as [WithSupervise], override its
+settings. Default is false.
+*/
+func WithFollowByName(enabled bool) Option {
+	return func(o *options) {
+		o.followByName = enabled
+		if enabled {
+			o.detectRotation = true
+			o.restartDelay = time.Second
+			o.maxRestarts = math.MaxInt
+		}
+	}
+}
+
+/*
+WithPathTruncationCheck also compares the read position with the size
+reported by a stat of the path when checking for truncation, not only
+with that of the open handle. On overlay filesystems, common in
+containers, the handle can keep reporting the old size for a while after
+a copytruncate, so the truncation would otherwise be missed and reading
+would resume in the middle of new content. It costs an extra stat per
+poll at the end of the file. Default is false.
+*/
+func WithPathTruncationCheck(enabled bool) Option {
+	return func(o *options) {
+		o.pathTruncationCheck = enabled
+	}
+}
+
+/*
+WithRotationIndexFunc sets the function that derives [Line].RotationIndex
+from the path of the file being read, for rotation schemes the default
+does not understand. It is called whenever the tailer moves to another
+path, such as a newer match of [FollowLatest]. By default a numeric
+suffix is used, as in "app.log.1" or "app.log.2.gz", and a path without
+one has index 0.
+*/
+func WithRotationIndexFunc(fn func(path string) int) Option {
+	return func(o *options) {
+		o.rotationIndex = fn
+	}
+}
+
+/*
+WithLineTimeout delivers an incomplete last line, with [Line].Partial set
+and no terminator, once it has gone d without growing, for interactive
+sources that may pause in the middle of a line. The flushed bytes count
+as delivered: whatever later completes the line is delivered as a line
+of its own, so a line written as "abc", a pause and "def\n" arrives as
+a partial "abc" followed by "def". A completion of just the terminator
+is an empty line and is skipped. It only applies to the default line
+framer. Zero, the default, holds incomplete lines until they are
+complete.
+*/
+func WithLineTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.lineTimeout = d
+	}
+}
+
+/*
+WithIdentityFunc replaces the device and inode comparison that rotation
+detection uses to tell whether the path still names the file being read,
+for rotation schemes where inodes mislead, such as copying content over
+a file that keeps its inode, or swapping symlinks. fn receives a stat of
+the file, once for the open file when it is opened and then for the path
+at each poll at the end of the file, together with the path followed,
+and returns an identity string; the file is considered rotated when the
+identity at the path differs from that of the open file. An error or an
+empty string means the identity is unknown, which never counts as a
+rotation. This also enables rotation detection on platforms where
+[RotationDetectionSupported] is false. Default is nil.
+*/
+func WithIdentityFunc(fn func(info os.FileInfo, path string) (string, error)) Option {
+	return func(o *options) {
+		o.identity = fn
+	}
+}
+
+/*
+WithArchivePattern names where rotation moves the followed file, such as
+"archive/app.log.*" for a scheme that moves app.log into a subdirectory
+instead of renaming it in place. The pattern is a [filepath.Match] glob,
+relative to the directory of the followed path unless absolute. While
+the tailer keeps its handle, a moved file is drained through the handle
+wherever it went, and no pattern is needed. The pattern matters when the
+handle is lost, as when [WithSupervise] recovers from an error: if the
+path then names a new file, the matches are searched for the old one,
+by inode, and reading resumes there, so that its rest is read before
+following the new file. Default is "", which always reopens the path.
+*/
+func WithArchivePattern(pattern string) Option {
+	return func(o *options) {
+		o.archivePattern = pattern
+	}
+}
+
+/*
+WithCaughtUpStable delays the caught-up signal, that is [Tailer.CaughtUp],
+the [WithCaughtUpMarker] callback and the in-band [CaughtUp] marker,
+until the tailer has been at the end of the file for d without reading a
+new line, so that a file under active write does not appear caught up
+during a momentary pause between writes. Each new line restarts the
+wait. The check runs at each poll, so the signal may come up to one poll
+interval after d. [Tailer.AtEOF] is not delayed and reports every
+arrival at the end of the file. Default is 0, which signals at the first
+end of file.
+*/
+func WithCaughtUpStable(d time.Duration) Option {
+	return func(o *options) {
+		o.caughtUpStable = d
+	}
+}
+
+/*
+WithLineDecorator registers fn to be called on each line read from the
+file once its standard fields are set, after [WithTransform] and
+[WithLevelParser] and before [WithFilter], so that it can attach derived
+data, such as a parsed field or a routing key, in [Line].Meta, which the
+filter can then use. fn runs in the tailing goroutine for every line and
+must be cheap. Lines queued by [WithSpillToDisk] pass through JSON, so
+Meta values should survive encoding/json; numbers, for example, come
+back as float64. Default is nil.
+*/
+func WithLineDecorator(fn func(*Line)) Option {
+	return func(o *options) {
+		o.decorate = fn
+	}
+}
+
+/*
+WithDelimiters splits the file into lines at any of the given bytes
+instead of only at '\n', for files written by producers that separate
+records differently, such as with the record separator '\x1e'. The
+delimiter that ended a line is its [Line].Terminator. When '\n' is one of
+the delimiters, a preceding '\r' is stripped with it as usual, see
+[WithStrictCRLF]; other delimiters are stripped alone. [WithMmap] is
+ignored with custom delimiters. Default is '\n' alone.
+*/
+func WithDelimiters(delims ...byte) Option {
+	return func(o *options) {
+		o.delimiters = string(delims)
+	}
+}
+
+/*
+WithReadTimeout bounds how long the tailer waits for data at the end of
+the file before checking it again for truncation and rotation, whatever
+the poll interval. It matters with [WithNotify] and a long fallback
+poll interval: a missed notification then delays rotation detection by
+at most d instead of a full poll interval. The context passed to
+[Follow] still ends the tailer at any time. Unlike [WithReadDeadline],
+which interrupts a single blocked read, it does not affect reads. Zero,
+the default, waits for the poll interval.
+*/
+func WithReadTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.readTimeout = d
+	}
+}
+
+/*
+WithStartAfterMarker skips the initial content of the file up to the
+first line for which match returns true, such as the banner that starts
+a session, and delivers normally from there. The marker line itself is
+delivered if inclusive is set. match sees each line's text after
+[WithTransform]. If no line matches by the time the end of the initial
+content is reached, fallback decides: [MarkerSkipAll] delivers only
+lines written later, [MarkerDeliverAll] reads the initial content again
+and delivers all of it. Lines written after the initial content are
+never searched. Default is nil, which skips nothing.
+*/
+func WithStartAfterMarker(match func(line string) bool, inclusive bool, fallback MarkerFallback) Option {
+	return func(o *options) {
+		o.startMarker = match
+		o.markerInclusive = inclusive
+		o.markerFallback = fallback
+	}
+}
+
+/*
+WithResumeToken starts tailing at the position recorded in tok, a token
+returned by [Tailer.Position] in an earlier session, instead of where
+[WithFromStart] or [WithStartAtPercent] would. Token offsets are at line
+boundaries, so the first line delivered is complete. If the file at the
+path is no longer the one tok was taken from, as after a rotation, or is
+now shorter than its offset, fallback decides: [ResumeFromEnd] follows
+from the end, [ResumeFromStart] reads the whole file, and [ResumeError]
+makes [Follow] fail with [ErrTokenMismatch]. Identities are compared as
+rotation detection does, with [WithIdentityFunc] if set. The zero Token,
+the default, resumes nothing.
+*/
+func WithResumeToken(tok Token, fallback ResumeFallback) Option {
+	return func(o *options) {
+		o.resumeToken = tok
+		o.resumeFallback = fallback
+	}
+}
+
+/*
+WithDedupRecent skips any line whose text hashes the same as one of the
+last n distinct lines seen, to suppress the short runs of lines
+delivered again when a replay overlaps what was already read, such as a
+[WithResumeToken] resume that falls back to the start of the file.
+Skipped lines are counted in [Stats].Duplicates. Lines that genuinely
+repeat within the window, such as identical heartbeats, are skipped as
+well, as are, very rarely, different lines whose 64-bit FNV-1a hashes
+collide, so only use it where the lines carry something unique such as
+a timestamp. Lines are compared before [WithFilter]. Zero, the
+default, disables it.
+*/
+func WithDedupRecent(n int) Option {
+	return func(o *options) {
+		o.dedupRecent = n
+	}
+}
+
+/*
+WithFollowSymlink says how a path that is a symbolic link is followed.
+With true, the default, the link is followed anew on every check, so
+pointing it at another file is a rotation to that file, as when a
+deployment switches a "current" link. With false, the link is resolved
+once, when the tailer starts or is [Tailer.Reset], and the file it then
+named is followed by its own path; later changes to the link are
+ignored. Either way, a path that turns from a regular file into a link,
+or back, is reopened: if it now names another file that is a rotation,
+and if it names the same file the handle is reopened at the same offset
+and the tailer carries on.
+*/
+func WithFollowSymlink(follow bool) Option {
+	return func(o *options) {
+		o.followSymlink = follow
+	}
+}
+
+/*
+WithReorder delivers lines in the order of a sequence number embedded in
+them, for files that several producers append to slightly out of order.
+seqFn extracts the number from a line's text, after [WithTransform];
+lines for which it returns false are delivered at once, as are lines
+arriving after a later number has already been delivered. Other lines
+are held back until the line with the next number arrives, or until
+more than window lines are held, when the lowest goes anyway. Held lines
+are all delivered, in order, when the initial backlog has been read,
+when the tailer has found no new data for a poll interval, on rotation,
+truncation and [WithStopAtEOF]; when the tailer is stopped, those for
+which the Lines channel has room are sent before it closes and the rest
+are counted in [Stats].Dropped. Default is nil, which keeps file order.
+*/
+func WithReorder(seqFn func(line string) (int64, bool), window int) Option {
+	return func(o *options) {
+		o.reorderSeq = seqFn
+		o.reorderWindow = window
+	}
+}
+
+/*
+WithScheduler makes the tailer poll its file on the ticks of sched,
+shared with other tailers, instead of on a timer of its own, which
+saves timer churn when many files are followed. The scheduler's
+interval then replaces the poll interval and [WithReadTimeout];
+notifications still wake the tailer at once, and [WithNotifyCoalesce]
+windows end on a tick. The tailer unregisters when it stops. [OpenPull]
+and [WithManualPoll] ignore it. Default is nil, a timer per tailer.
+*/
+func WithScheduler(sched *Scheduler) Option {
+	return func(o *options) {
+		o.scheduler = sched
+	}
+}
+
+/*
+WithSkipHeaderBytes skips the first n bytes of the file, such as the
+fixed-size header of a log container, so that they are not read as a
+line. It only applies when reading starts at the very beginning of the
+file: with [WithFromStart], or on a file that was truncated, rotated in
+or replayed, but not when starting at its end, part way in with
+[WithStartAtPercent] or at a [WithResumeToken] offset past the header.
+Until the file holds n bytes, nothing is read from it. Default is 0.
+*/
+func WithSkipHeaderBytes(n int64) Option {
+	return func(o *options) {
+		o.skipHeader = n
+	}
+}
+
+/*
+WithOnHeader calls fn with the header skipped by [WithSkipHeaderBytes]
+each time one is read, for callers that want to parse it. fn runs in
+the tailing goroutine and may keep the slice. Default is nil.
+*/
+func WithOnHeader(fn func(header []byte)) Option {
+	return func(o *options) {
+		o.onHeader = fn
+	}
+}
+
+/*
+WithProgress calls fn every interval while the initial content is
+replayed, with the bytes read so far ([Stats].BytesRead), the size of
+the file when it was opened ([Tailer.InitialSize], -1 if unknown) and
+the lines delivered so far ([Stats].Lines), for progress bars and log
+messages. fn is called a last time when the tailer catches up, as
+[Tailer.CaughtUp] fires, and not after that. It runs on a goroutine of
+its own, never per line, and must not block for long. [OpenPull]
+ignores it. Default is nil.
+*/
+func WithProgress(every time.Duration, fn func(bytesRead, totalBytes, linesRead int64)) Option {
+	return func(o *options) {
+		o.progressEvery = every
+		o.progress = fn
+	}
+}
+
+/*
+WithPrefixAllow delivers only lines whose text starts with one of the
+given prefixes, such as "ERROR" and "WARN". It is a much cheaper test
+than a [WithFilter] predicate running a regular expression. The prefix
+lists are checked before the WithFilter predicate, which only sees the
+lines they let through; a line on both lists is dropped, as
+[WithPrefixDeny] takes precedence. Dropped lines can still be delivered
+as context lines (see [WithContextLines]). Text is matched after
+[WithTransform]. Default is no list, which allows every line.
+*/
+func WithPrefixAllow(prefixes ...string) Option {
+	return func(o *options) {
+		o.prefixAllow = newPrefixSet(prefixes)
+	}
+}
+
+/*
+WithPrefixDeny drops lines whose text starts with one of the given
+prefixes, such as "DEBUG". It takes precedence over [WithPrefixAllow],
+and like it is checked before [WithFilter]. Default is no list.
+*/
+func WithPrefixDeny(prefixes ...string) Option {
+	return func(o *options) {
+		o.prefixDeny = newPrefixSet(prefixes)
+	}
+}
+
+/*
+WithAlignToLine sets whether a start inside a line, as with
+[WithStartAtPercent], skips the rest of that line so that the first
+line delivered is complete. If false, the torn fragment from the
+starting position to the next line terminator is delivered as is, with
+[Line].Partial set. Offsets of [WithResumeToken] are at line
+boundaries, so a resumed tailer starts with a complete line either way.
+Default is true.
+*/
+func WithAlignToLine(align bool) Option {
+	return func(o *options) {
+		o.alignToLine = align
+	}
+}
+
+/*
+WithLifecycleEvents sends every change in the state of the tailer to ch
+as a [LifecycleEvent], from [LifecycleStarted] to [LifecycleStopped], so
+that one select can log them all or drive a state machine. The channel
+carries what the separate means of observation report, and can replace
+them: the events of [WithEventHandler], the [WithCaughtUpMarker]
+callback, the [Tailer.CaughtUp] and [Tailer.Done] channels and the
+error of [Tailer.Err], plus pauses, resumes and fatal errors that
+[WithSupervise] recovers from. Those all keep working alongside it.
+
+Sends never block the tailer: an event is dropped if ch is not ready,
+so give it a buffer. The tailer does not close ch.
+*/
+func WithLifecycleEvents(ch chan<- LifecycleEvent) Option {
+	return func(o *options) {
+		o.lifecycle = ch
+	}
+}
+
+/*
+WithFixedRecordSize frames the file as a sequence of records of exactly
+n bytes with no delimiter, as some binary and telemetry logs are
+written. Each record is delivered as a Line with [Line].Raw holding its
+bytes and Text the same bytes as a string, which is what [WithTransform]
+and [WithFilter] see. A record is only delivered once all n bytes have
+been written; a partial record at the end of the file waits for the
+rest, and is discarded on truncation or rotation like an incomplete
+line. [WithStartAtPercent] starts at the record boundary before its
+position. Like [WithFramer], it replaces the default framing; whichever
+of the two comes last applies. A size below 1 is taken as 1.
+*/
+func WithFixedRecordSize(n int) Option {
+	return func(o *options) {
+		o.framer = fixedFramer{size: max(n, 1)}
+	}
+}
+
+/*
+WithAggregator feeds every line read to agg, so that a live summary
+such as [CountByLevel] is kept by the tailer itself; fetch it with
+[Tailer.Aggregate]. Lines are observed in the tailing goroutine as they
+are read, after [WithTransform], [WithLevelParser] and
+[WithLineDecorator] and before [WithDedupRecent] and the filters, and
+again when they are read again, as after a truncation or a replay.
+Marker lines are not observed. Default is no aggregator.
+*/
+func WithAggregator(agg Aggregator) Option {
+	return func(o *options) {
+		o.aggregator = agg
+	}
+}
+
+/*
+WithInitialBytes replays at most the last n bytes of the file before
+following it, for a bounded amount of recent context from a file too
+large to read whole. Reading starts n bytes before the end, or at the
+start if the file is smaller; if that falls inside a line, the rest of
+it is skipped as with [WithStartAtPercent] (see [WithAlignToLine]), so
+fewer than n bytes may be delivered. Overrides [WithFromStart];
+[WithStartAtPercent] takes precedence. Default is 0, which sets no cap.
+*/
+func WithInitialBytes(n int64) Option {
+	return func(o *options) {
+		o.initialBytes = n
+	}
+}
+
+/*
+WithCompressedResume lets a [WithResumeToken] resume find the file it
+was taken from after that file has been compressed, as pipelines that
+rotate a log and gzip it later do. successor maps the path to the name
+of its compressed successor, such as path+".gz". If the file at the
+path is not the one the token was taken from, or no longer exists, and
+the successor exists and holds at least the token's offset of content,
+the rest of its decompressed content is delivered first, and then the
+file at the path from its start, as the file that replaced it; a
+missing path is waited for as under [WithFollowByName]. Otherwise the
+fallback of the token applies. Positions taken while the successor is
+being read point at the start of the file at the path. Default is nil.
+*/
+func WithCompressedResume(successor func(path string) string) Option {
+	return func(o *options) {
+		o.compressedResume = successor
+	}
+}
+
+/*
+WithMaxFileSize stops the tailer once the file has grown beyond n bytes,
+with [Tailer.Err] reporting a [*TailError] that wraps [ErrFileTooLarge],
+as a guard against a runaway producer. The size is checked whenever the
+tailer reaches the end of the file, so every line read up to then has
+been delivered. The limit applies to each file on its own: a file that
+replaces it at a rotation, or a truncation, starts again from zero.
+[WithSupervise] does not restart after it. Default is 0, no limit.
+*/
+func WithMaxFileSize(n int64) Option {
+	return func(o *options) {
+		o.maxFileSize = n
+	}
+}
+
+/*
+WithWriterMmap supports files that their writer fills through a memory
+mapping, as some databases do. Such a file is sized ahead of its
+content, and the space not yet written reads as NUL bytes, so the size
+cannot tell how much has been written. With this option the first NUL
+byte is taken as the end of the file: reading stops there and polls for
+it to be written, and following from the end starts there too. Lines
+therefore must not contain NUL bytes. Stuck handle detection and
+[WithMmap] are disabled, and a rotated file is not drained before
+switching to its replacement. Default is false.
+*/
+func WithWriterMmap(enabled bool) Option {
+	return func(o *options) {
+		o.writerMmap = enabled
+	}
+}
+
+/*
+WithDebounce makes [FollowContents] wait until a changed file has stayed
+the same for d before reading it, so that rapid rewrites are delivered
+once, as their final result. The first contents are read without
+waiting. It has no effect on other tailers. Default is 0, which reads a
+change as soon as it is noticed.
+*/
+func WithDebounce(d time.Duration) Option {
+	return func(o *options) {
+		o.debounce = d
+	}
+}
+
+/*
+WithPressureSignal sends the tailer's backpressure to ch about ten times
+a second, for a writer in the same program that can slow down while the
+consumer falls behind. The value runs from 0, nothing waiting to be
+received, to 1, the Lines channel full; under [WithMemoryBudget] it is
+the larger of that and the share of the budget in use. Sends never
+block: a value ch has no room for is dropped, so a writer that has not
+received for a while may first get an old reading. No value is sent
+once [Tailer.Done] is closed, so ch may be closed after that. The
+signal is advisory. The tailer behaves the same whether or not anyone
+acts on it, and [WithOverflowPolicy] still decides what happens to
+lines that do not fit. It has no effect on a [PullTailer]. Default is
+nil.
+*/
+func WithPressureSignal(ch chan<- float64) Option {
+	return func(o *options) {
+		o.pressure = ch
+	}
+}
+
+/*
+WithFromStartOnce reads the file the tailer starts on from the beginning,
+as [WithFromStart] does, but each file it rotates to afterwards from its
+end, for setups where rotated files are ingested separately, such as by
+an archive pipeline, and reading their history again would duplicate it.
+Lines written to a new file between its creation and the tailer noticing
+the rotation, up to about a poll interval, are skipped along with the
+rest. Restarts after truncation, switches to [WithFallbackPaths] and the
+next file of [FollowLatest] or [FollowDaily] still read from the start,
+as does compressed input. Passing false does not undo [WithFromStart].
+Default is false.
+*/
+func WithFromStartOnce(enabled bool) Option {
+	return func(o *options) {
+		if enabled {
+			o.fromStart = true
+		}
+		o.fromStartOnce = enabled
+	}
+}
+
+/*
+WithRotationDrainTimeout bounds how long the tailer keeps reading a file
+that was rotated away before it switches to the new file at the path.
+Lines written to the old file after the tailer's last read are normally
+drained first, so that none are lost, but a process that keeps the old
+file open and goes on writing to it would hold the tailer there
+indefinitely. After d the tailer switches anyway and counts the bytes
+left unread in [Stats].DroppedOnRotation. A longer d loses fewer lines
+from a slow writer; a shorter one picks up the new file sooner. Zero or
+less drains for as long as the old file grows. Default is 2s.
+*/
+func WithRotationDrainTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.rotationDrainTimeout = d
+	}
+}
+
+/*
+WithTextDecoding makes [FollowContents] convert each payload to UTF-8
+text with "\n" line endings. A byte order mark says whether the file is
+UTF-16, little- or big-endian, or UTF-8, and is removed; a file without
+one is taken to be UTF-16LE if it starts with an ASCII character encoded
+that way, as the text exports of some Windows tools do, and UTF-8
+otherwise. "\r\n" line endings become "\n". It has no effect on other
+tailers, which read UTF-8 and other ASCII-compatible encodings only.
+Default is false.
+*/
+func WithTextDecoding(enabled bool) Option {
+	return func(o *options) {
+		o.textDecoding = enabled
+	}
+}
+
+/*
+WithAppendOnly declares that the file only ever grows: it is never
+truncated, rotated or replaced, as on a write-once volume. The tailer
+then skips the checks it makes each time it reaches the end of the file,
+among them a seek and stats of the handle and of the path, which makes
+a poll that finds no new data several times cheaper. Truncation,
+rotation, stuck handles, [WithRestartOnShrink], [WithPathTruncationCheck],
+[WithFallbackPaths] and [WithMaxFileSize] all go unchecked, so a file
+that does change otherwise is followed wrongly, or not at all. It
+combines with [WithStopAtEOF], which makes no such checks in the first
+place. Default is false.
+*/
+func WithAppendOnly(enabled bool) Option {
+	return func(o *options) {
+		o.appendOnly = enabled
+	}
+}
+
+/*
+WithFilterChain filters lines by the ordered allow and deny rules of
+chain: the first rule that matches a line decides whether it is
+delivered, and chain.DefaultDeny decides for lines no rule matches.
+See [FilterChain]. The chain is checked after [WithPrefixAllow] and
+[WithPrefixDeny] and before [WithFilter], which only sees the lines it
+lets through. Dropped lines can still be delivered as context lines
+(see [WithContextLines]). Text is matched after [WithTransform].
+Default is no chain, which allows every line.
+*/
+func WithFilterChain(chain FilterChain) Option {
+	chain.Rules = slices.Clone(chain.Rules)
+	return func(o *options) {
+		o.filterChain = &chain
+	}
+}
+
+/*
+WithRescanInterval sets how often [FollowGlob] matches its pattern again
+to find new files. Values of zero or less are ignored. It has no effect
+on other tailers. Default is 1s.
+*/
+func WithRescanInterval(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.rescanInterval = d
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package tailf
+
+import "time"
+
+// PresetContainerLogs returns options for the logs of containers as
+// written by Kubernetes and container runtimes, such as the files under
+// /var/log/containers, which are symbolic links to files that the kubelet
+// rotates by renaming them and creating a new one. Like the other
+// presets, pass it first and add options after it to override single
+// settings:
+//
+//	opts := append(tailf.PresetContainerLogs(), tailf.WithFromStart(true))
+//
+// It sets:
+//
+//   - [WithFollowByName](true): wait for a log that does not exist yet,
+//     follow rotation by name, and reopen after errors.
+//   - [WithFollowSymlink](true): follow the link anew on every check,
+//     so a link repointed to a new container's log is followed.
+//   - [WithPathTruncationCheck](true): notice truncation that a handle
+//     on overlay storage reports late.
+func PresetContainerLogs() []Option {
+	return []Option{
+		WithFollowByName(true),
+		WithFollowSymlink(true),
+		WithPathTruncationCheck(true),
+	}
+}
+
+// PresetLogrotate returns options for classic log files managed by
+// logrotate or newsyslog, whether configured to rename and create or
+// to copytruncate. It sets:
+//
+//   - [WithFollowByName](true): follow the new file created after a
+//     rename, also across the moment where the path does not exist, and
+//     reopen after errors.
+//   - [WithRestartOnShrink](true): read from the start whenever the file
+//     got smaller, even if a copytruncate was followed by enough writes
+//     to pass the old read position by the time it is checked.
+func PresetLogrotate() []Option {
+	return []Option{
+		WithFollowByName(true),
+		WithRestartOnShrink(true),
+	}
+}
+
+// PresetWindowsEventText returns options for text logs that Windows
+// applications append to. Change notifications are often unavailable to
+// a tailer there, and an application may rewrite its log rather than
+// rotate it. It sets:
+//
+//   - [WithFollowByName](true): wait for a log that does not exist yet
+//     and reopen the path after errors, such as a sharing violation
+//     while the application rotates it.
+//   - [WithRestartOnShrink](true): read from the start when the file is
+//     rewritten smaller, even if the application kept its identity.
+//   - [WithPollInterval](250ms): poll less often than the default, since
+//     polling is all there is.
+//
+// Lines ending in "\r\n" are handled by default. Logs in UTF-16, such as
+// event log exports, cannot be appended to in a way a line tailer can
+// follow; exports are rewritten whole anyway, so follow them with
+// [FollowContents], [WithTextDecoding] and [SplitLines] instead.
+func PresetWindowsEventText() []Option {
+	return []Option{
+		WithFollowByName(true),
+		WithRestartOnShrink(true),
+		WithPollInterval(250 * time.Millisecond),
+	}
+}
@@ -0,0 +1,37 @@
+package tailf
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPresets(t *testing.T) {
+	apply := func(opts []Option) options {
+		o := defaults()
+		for _, opt := range opts {
+			opt(&o)
+		}
+		return o
+	}
+
+	o := apply(PresetContainerLogs())
+	if !o.followByName || !o.followSymlink || !o.pathTruncationCheck {
+		t.Errorf("PresetContainerLogs: got %+v", o)
+	}
+
+	o = apply(PresetLogrotate())
+	if !o.followByName || !o.detectRotation || !o.restartOnShrink {
+		t.Errorf("PresetLogrotate: got %+v", o)
+	}
+
+	o = apply(PresetWindowsEventText())
+	if !o.followByName || !o.restartOnShrink || o.pollInterval != 250*time.Millisecond {
+		t.Errorf("PresetWindowsEventText: got %+v", o)
+	}
+
+	// Options after a preset override it.
+	o = apply(append(PresetWindowsEventText(), WithPollInterval(time.Second), WithSupervise(0, 0)))
+	if o.pollInterval != time.Second || o.maxRestarts != 0 {
+		t.Errorf("override: got poll interval %v, max restarts %d", o.pollInterval, o.maxRestarts)
+	}
+}
@@ -0,0 +1,42 @@
+package tailf
+
+import "time"
+
+// pressureInterval is how often [WithPressureSignal] reports.
+const pressureInterval = 100 * time.Millisecond
+
+// pressure returns how full the tailer's delivery queue is, from 0 to 1:
+// the share of the Lines channel taken or, under [WithMemoryBudget], the
+// share of the budget, whichever is larger.
+func (t *Tailer) pressure(budget int64) float64 {
+	p := float64(len(t.lines)) / float64(cap(t.lines))
+	if budget > 0 {
+		t.mu.Lock()
+		used := t.sent.buffered(len(t.lines)) + t.held
+		t.mu.Unlock()
+		p = max(p, float64(used)/float64(budget))
+	}
+	return min(p, 1)
+}
+
+// signalPressure sends the tailer's pressure to ch every
+// pressureInterval until it stops, dropping a value that ch has no room
+// for. It runs on a helper goroutine, so a writer that stops receiving
+// cannot hold up the tailer, and sends nothing once Done is closed.
+func (t *Tailer) signalPressure(ch chan<- float64, budget int64) {
+	t.helper(func() {
+		ticker := time.NewTicker(pressureInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				select {
+				case ch <- t.pressure(budget):
+				default:
+				}
+			case <-t.stopping:
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,39 @@
+package tailf
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPressureSignal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	writeNumbered(t, path, 2*lineBuffer)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pressure := make(chan float64, 1)
+	tailer, err := Follow(ctx, path, WithFromStart(true), WithPressureSignal(pressure))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nobody receives, so the channel fills up.
+	for tailer.Pending() < lineBuffer {
+		time.Sleep(time.Millisecond)
+	}
+	<-pressure // may predate the channel filling up
+	if got := <-pressure; got != 1 {
+		t.Errorf("full channel: got %v, want 1", got)
+	}
+
+	for range 2 * lineBuffer {
+		<-tailer.Lines()
+	}
+	<-pressure
+	if got := <-pressure; got != 0 {
+		t.Errorf("drained channel: got %v, want 0", got)
+	}
+}
@@ -0,0 +1,88 @@
+package tailf
+
+import (
+	"os"
+	"time"
+)
+
+// InitialSize returns the size of the file when it was opened, the total
+// a progress display can measure [Stats].BytesRead against while the backlog
+// is replayed. It is -1 for sources whose size is not meaningful, such
+// as pipes, and for a [WithFollowByName] tailer still waiting for its
+// file. It is safe to call concurrently.
+func (t *Tailer) InitialSize() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.initialSize
+}
+
+// AtEOF reports whether the last read reached the end of the file, that
+// is, whether every complete line written so far has been read. Together
+// with [Tailer.CaughtUp], which fires the first time this happens, it
+// tells a viewer when replay is done. AtEOF is instantaneous, unlike
+// CaughtUp under [WithCaughtUpStable]. It is safe to call concurrently.
+func (t *Tailer) AtEOF() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.atEOF
+}
+
+// setAtEOF records whether the tailer is at the end of the file, keeping
+// the tailing goroutine's copy and the one read by [Tailer.AtEOF] in
+// sync.
+func (s *tailState) setAtEOF(atEOF bool) {
+	if s.atEOF == atEOF {
+		return
+	}
+	s.atEOF = atEOF
+	if atEOF {
+		s.eofSince = time.Now()
+	}
+	s.t.mu.Lock()
+	s.t.atEOF = atEOF
+	s.t.mu.Unlock()
+}
+
+// setInitialSize records the size of file for [Tailer.InitialSize].
+func (t *Tailer) setInitialSize(file *os.File) {
+	size := int64(-1)
+	if info, err := file.Stat(); err == nil && info.Mode().IsRegular() {
+		size = info.Size()
+	}
+	t.mu.Lock()
+	t.initialSize = size
+	t.mu.Unlock()
+}
+
+// InitialSize returns the size of the file when it was opened; see
+// [Tailer.InitialSize].
+func (p *PullTailer) InitialSize() int64 {
+	return p.s.t.InitialSize()
+}
+
+// reportProgress calls fn every interval with the bytes read, the
+// initial size and the lines delivered, until the tailer catches up,
+// when it calls fn a last time, or stops. It runs on a helper goroutine,
+// so that replay pays nothing per line for it, and never calls fn once
+// Done is closed.
+func (t *Tailer) reportProgress(every time.Duration, fn func(bytesRead, totalBytes, linesRead int64)) {
+	report := func() {
+		st := t.Stats()
+		fn(st.BytesRead, t.InitialSize(), st.Lines)
+	}
+	t.helper(func() {
+		ticker := time.NewTicker(max(every, minPollInterval))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				report()
+			case <-t.caughtUp:
+				report()
+				return
+			case <-t.stopping:
+				return
+			}
+		}
+	})
+}
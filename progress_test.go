@@ -0,0 +1,189 @@
+package tailf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestInitialSizeAndAtEOF(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	data := "one\ntwo\nthree\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFromStart(true), WithPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tailer.InitialSize(); got != int64(len(data)) {
+		t.Errorf("InitialSize = %d, want %d", got, len(data))
+	}
+
+	select {
+	case <-tailer.CaughtUp():
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for catch-up")
+	}
+	if !tailer.AtEOF() {
+		t.Error("AtEOF = false after catching up")
+	}
+	if got := tailer.Stats().BytesRead; got != tailer.InitialSize() {
+		t.Errorf("BytesRead = %d after catching up, want %d", got, tailer.InitialSize())
+	}
+
+	// Growth after opening does not change the initial size.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("four\n")
+	f.Close()
+	for _, want := range []string{"one", "two", "three", "four"} {
+		select {
+		case line := <-tailer.Lines():
+			if line.Text != want {
+				t.Errorf("got %q, want %q", line.Text, want)
+			}
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for %q", want)
+		}
+	}
+	if got := tailer.InitialSize(); got != int64(len(data)) {
+		t.Errorf("InitialSize after growth = %d, want %d", got, len(data))
+	}
+
+	cancel()
+	<-tailer.Done()
+}
+
+func TestInitialSizeFollowByNameWaiting(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tailer, err := Follow(ctx, filepath.Join(t.TempDir(), "missing.log"), WithFollowByName(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tailer.InitialSize(); got != -1 {
+		t.Errorf("InitialSize while waiting = %d, want -1", got)
+	}
+	cancel()
+	<-tailer.Done()
+}
+
+func TestCaughtUpStable(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("backlog\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	const stable = 100 * time.Millisecond
+	tailer, err := Follow(ctx, path,
+		WithFromStart(true),
+		WithPollInterval(5*time.Millisecond),
+		WithCaughtUpStable(stable),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for range tailer.Lines() {
+		}
+	}()
+
+	// Keep writing with pauses shorter than the stable period.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	for i := 0; i < 8; i++ {
+		f.WriteString("live\n")
+		time.Sleep(stable / 4)
+		select {
+		case <-tailer.CaughtUp():
+			t.Fatalf("caught up during active writes, after write %d", i)
+		default:
+		}
+	}
+	lastWrite := time.Now()
+
+	select {
+	case <-tailer.CaughtUp():
+		if waited := time.Since(lastWrite); waited < stable/2 {
+			t.Errorf("caught up %v after the last write, want about %v", waited, stable)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for catch-up")
+	}
+	if !tailer.AtEOF() {
+		t.Error("AtEOF = false after catching up")
+	}
+
+	cancel()
+	<-tailer.Done()
+}
+
+func TestFollowProgress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	writeNumbered(t, path, 2000)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	type report struct{ read, total, lines int64 }
+	reports := make(chan report, 10000)
+	tailer, err := Follow(ctx, path,
+		WithFromStart(true),
+		WithProgress(time.Millisecond, func(read, total, lines int64) {
+			reports <- report{read, total, lines}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Receive slowly so that the replay spans several reports.
+	for i := 0; i < 2000; i++ {
+		<-tailer.Lines()
+		if i%100 == 0 {
+			time.Sleep(2 * time.Millisecond)
+		}
+	}
+	<-tailer.CaughtUp()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	<-tailer.Done()
+	close(reports)
+
+	var got []report
+	for r := range reports {
+		got = append(got, r)
+	}
+	if len(got) < 2 {
+		t.Fatalf("got %d reports, want several", len(got))
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i].read < got[i-1].read || got[i].lines < got[i-1].lines {
+			t.Fatalf("report %d went backwards: %+v after %+v", i, got[i], got[i-1])
+		}
+	}
+	size := tailer.InitialSize()
+	if last := got[len(got)-1]; last.read != size || last.total != size || last.lines != 2000 {
+		t.Errorf("last report = %+v, want %d of %d bytes and 2000 lines", last, size, size)
+	}
+}
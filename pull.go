@@ -0,0 +1,87 @@
+package tailf
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// PullTailer follows a file without a background goroutine. All reading,
+// polling and rotation handling happens inline in [PullTailer.Next], on
+// the caller's goroutine, which gives full control over when I/O occurs.
+//
+// A PullTailer is not safe for concurrent use.
+type PullTailer struct {
+	s   *tailState
+	err error
+}
+
+// OpenPull opens path for pull-based tailing. Options apply as for
+// [Follow], except that [WithMmap] is ignored since only the tailing
+// goroutine of [Follow] replays from a mapping. ctx is only used while
+// opening the file; pass a context to each [PullTailer.Next] call to
+// bound the wait for data.
+func OpenPull(ctx context.Context, path string, opts ...Option) (*PullTailer, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("tailf: %w", err)
+	}
+
+	o := defaults()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	o.followByName = false
+	s, err := newTailState(path, o)
+	if err != nil {
+		return nil, fmt.Errorf("tailf: %w", err)
+	}
+	s.pull = true
+	return &PullTailer{s: s}, nil
+}
+
+// Next returns the next line, reading, polling and handling truncation
+// and rotation as needed. It blocks until a line is available or ctx is
+// done, in which case it returns ctx's error; the PullTailer remains
+// usable with a new context. With [WithStopAtEOF], Next returns io.EOF
+// once every line up to the end of the file has been returned, or
+// [ErrTrailingPartial] if [WithRequireFinalNewline] withheld an
+// incomplete last line. Any other
+// error is fatal and is returned by all later calls.
+func (p *PullTailer) Next(ctx context.Context) (Line, error) {
+	s := p.s
+	for len(s.queue) == 0 {
+		if p.err != nil {
+			return Line{}, p.err
+		}
+		if err := ctx.Err(); err != nil {
+			return Line{}, err
+		}
+		if s.stopped {
+			p.err = io.EOF
+			if err := s.t.Err(); err != nil {
+				p.err = err
+			}
+			continue
+		}
+		if _, err := s.step(ctx); err != nil {
+			p.err = err
+		}
+	}
+
+	l := s.queue[0]
+	s.queue[0] = Line{}
+	s.queue = s.queue[1:]
+	return l, nil
+}
+
+// Stats returns a snapshot of the tailer's counters.
+func (p *PullTailer) Stats() Stats {
+	return p.s.t.Stats()
+}
+
+// Close releases the file handle. Next must not be called afterwards.
+func (p *PullTailer) Close() error {
+	p.err = ErrStopped
+	return p.s.file.Close()
+}
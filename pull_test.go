@@ -0,0 +1,69 @@
+package tailf
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPullTailer(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := OpenPull(context.Background(), path, WithFromStart(true), WithPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	for _, want := range []string{"one", "two"} {
+		line, err := p.Next(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line.Text != want {
+			t.Errorf("got %q, want %q", line.Text, want)
+		}
+	}
+
+	// Nothing more to read: Next waits for data until its context ends,
+	// and the tailer remains usable afterwards.
+	short, cancelShort := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancelShort()
+	if _, err := p.Next(short); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want DeadlineExceeded", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("three\n")
+	f.Close()
+
+	line, err := p.Next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line.Text != "three" {
+		t.Errorf("got %q, want %q", line.Text, "three")
+	}
+	if got := p.Stats().Lines; got != 3 {
+		t.Errorf("Lines = %d, want 3", got)
+	}
+
+	p.Close()
+	if _, err := p.Next(ctx); !errors.Is(err, ErrStopped) {
+		t.Errorf("Next after Close: got %v, want ErrStopped", err)
+	}
+}
@@ -0,0 +1,127 @@
+package tailf
+
+import (
+	"cmp"
+	"context"
+	"slices"
+)
+
+// reorderBuffer holds lines back for [WithReorder] until they can be
+// released in the order of their sequence numbers.
+type reorderBuffer struct {
+	held []seqLine // sorted by seq, in arrival order for equal ones
+
+	// last is the sequence number of the last line released; started
+	// is set once there is one.
+	last    int64
+	started bool
+}
+
+type seqLine struct {
+	seq int64
+	l   Line
+	at  linePos
+}
+
+// push adds l, whose sequence number is seq and which was read at at, to
+// the held lines.
+func (b *reorderBuffer) push(seq int64, l Line, at linePos) {
+	i, _ := slices.BinarySearchFunc(b.held, seq+1, func(h seqLine, seq int64) int {
+		return cmp.Compare(h.seq, seq)
+	})
+	b.held = slices.Insert(b.held, i, seqLine{seq, l, at})
+}
+
+// pop removes and returns the held line with the lowest sequence number,
+// and where it was read.
+func (b *reorderBuffer) pop() (Line, linePos) {
+	h := b.held[0]
+	b.held = slices.Delete(b.held, 0, 1)
+	b.last, b.started = h.seq, true
+	return h.l, h.at
+}
+
+// next reports whether the lowest held line directly follows the last
+// one released.
+func (b *reorderBuffer) next() bool {
+	return len(b.held) > 0 && b.started && b.held[0].seq == b.last+1
+}
+
+func (b *reorderBuffer) reset() {
+	clear(b.held)
+	b.held = b.held[:0]
+	b.started = false
+}
+
+// reorder delivers l under [WithReorder]: it is held back with the
+// lines waiting for a gap in the sequence to be filled, and the lines
+// that no longer need to wait are released. It returns false if ctx was
+// cancelled.
+func (s *tailState) reorder(ctx context.Context, l Line) bool {
+	seq, ok := s.o.reorderSeq(l.Text)
+	b := s.reordering
+	if !ok || b.started && seq <= b.last {
+		// Nothing to order it by, or too late to be put in order.
+		return s.deliverFiltered(ctx, l)
+	}
+	b.push(seq, l, s.sendingAt)
+
+	epoch := s.epoch
+	for b.next() || len(b.held) > s.o.reorderWindow {
+		if !s.deliverHeld(ctx) {
+			return false
+		}
+		if s.epoch != epoch {
+			return true
+		}
+	}
+	return true
+}
+
+// deliverHeld delivers the held line with the lowest sequence number.
+func (s *tailState) deliverHeld(ctx context.Context) bool {
+	l, at := s.reordering.pop()
+	s.sendingAt = at
+	return s.deliverFiltered(ctx, l)
+}
+
+// flushReorder delivers all the lines held back by [WithReorder], in
+// order, whatever gaps remain. It returns false if ctx was cancelled.
+func (s *tailState) flushReorder(ctx context.Context) bool {
+	b := s.reordering
+	if b == nil {
+		return true
+	}
+	epoch := s.epoch
+	for len(b.held) > 0 {
+		if !s.deliverHeld(ctx) {
+			return false
+		}
+		if s.epoch != epoch {
+			return true
+		}
+	}
+	return true
+}
+
+// drainReorder hands the lines still held back by [WithReorder] when the
+// tailer stops to the Lines channel, as far as it has room for them, and
+// counts the rest as dropped.
+func (s *tailState) drainReorder() {
+	b := s.reordering
+	if b == nil || s.pull {
+		return
+	}
+	for len(b.held) > 0 {
+		l, _ := b.pop()
+		if !s.o.accepts(l) {
+			continue
+		}
+		select {
+		case s.t.lines <- l:
+			s.t.recordSent(l)
+		default:
+			s.t.updateStats(func(st *Stats) { st.Dropped++ })
+		}
+	}
+}
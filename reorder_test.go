@@ -0,0 +1,134 @@
+package tailf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// leadingSeq reads the sequence number at the start of a line.
+func leadingSeq(line string) (int64, bool) {
+	field, _, _ := strings.Cut(line, " ")
+	n, err := strconv.ParseInt(field, 10, 64)
+	return n, err == nil
+}
+
+func TestReadAllReorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	content := "1 a\n3 c\n2 b\nno seq\n5 e\n4 d\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lines, err := ReadAll(context.Background(), path, WithReorder(leadingSeq, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, l := range lines {
+		got = append(got, l.Text)
+	}
+
+	// Lines without a number are not held back.
+	want := "no seq,1 a,2 b,3 c,4 d,5 e"
+	if strings.Join(got, ",") != want {
+		t.Errorf("got %q, want %q", strings.Join(got, ","), want)
+	}
+}
+
+func TestReadAllReorderWindow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	content := "3\n1\n2\n6\n5\n4\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// With room for two lines, "3" and "1" wait and "2" forces "1" out;
+	// "2" and "3" then follow in sequence. "6" and "5" wait until "4",
+	// next in sequence, releases them.
+	lines, err := ReadAll(context.Background(), path, WithReorder(leadingSeq, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, l := range lines {
+		got = append(got, l.Text)
+	}
+	if want := "1,2,3,4,5,6"; strings.Join(got, ",") != want {
+		t.Errorf("got %q, want %q", strings.Join(got, ","), want)
+	}
+}
+
+func TestFollowReorderFlushesWhenIdle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path,
+		WithPollInterval(10*time.Millisecond),
+		WithReorder(leadingSeq, 100),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-tailer.CaughtUp()
+
+	// "1" never arrives: the gap is given up once the file goes quiet.
+	appendLine(t, path, "3 c")
+	appendLine(t, path, "2 b")
+	for _, want := range []string{"2 b", "3 c"} {
+		if got := nextLine(ctx, t, tailer); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+
+	// A line behind those already delivered goes straight through.
+	appendLine(t, path, "1 a")
+	if got := nextLine(ctx, t, tailer); got != "1 a" {
+		t.Errorf("got %q, want %q", got, "1 a")
+	}
+}
+
+func TestFollowReorderFlushesOnRotation(t *testing.T) {
+	if !RotationDetectionSupported() {
+		t.Skip("no file identities on this platform")
+	}
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// A poll interval long enough that only the rotation flushes.
+	tailer, err := Follow(ctx, path,
+		WithPollInterval(time.Hour),
+		WithReadTimeout(10*time.Millisecond),
+		WithReorder(leadingSeq, 100),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-tailer.CaughtUp()
+	appendLine(t, path, "2 b")
+
+	tmp := path + ".new"
+	if err := os.WriteFile(tmp, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+	if got := nextLine(ctx, t, tailer); got != "2 b" {
+		t.Errorf("got %q, want %q", got, "2 b")
+	}
+}
@@ -0,0 +1,51 @@
+package tailf
+
+import (
+	"io"
+	"os"
+	"os/signal"
+)
+
+// watchReplaySignal rewinds the tailer to the start of its file each
+// time the [WithReplaySignal] signal arrives, until the tailer stops. It
+// runs on a helper goroutine, so no rewind is attempted once Done is
+// closed.
+func (t *Tailer) watchReplaySignal(sig os.Signal) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, sig)
+	t.helper(func() {
+		defer signal.Stop(sigs)
+		for {
+			select {
+			case <-sigs:
+				t.do(func(s *tailState) error {
+					return s.replay()
+				})
+			case <-t.stopping:
+				return
+			}
+		}
+	})
+}
+
+// replay seeks the current handle back to the start of the file, so
+// that everything is read and delivered again. A line being delivered
+// is dropped, since it will be read again.
+func (s *tailState) replay() error {
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return s.fail("seek", err)
+	}
+	s.resetReader()
+	s.scan.torn = false
+	s.pending = 0
+	s.stuckPolls = 0
+	s.before.reset()
+	s.afterLeft = 0
+	s.epoch++
+	s.generation++
+	if s.reordering != nil {
+		s.reordering.reset()
+	}
+	s.emit(EventReopened)
+	return nil
+}
@@ -0,0 +1,66 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package tailf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReplaySignal(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	events := make(chan Event, 4)
+	tailer, err := Follow(ctx, path,
+		WithFromStart(true),
+		WithPollInterval(10*time.Millisecond),
+		WithReplaySignal(syscall.SIGUSR1),
+		WithEventHandler(func(e Event) { events <- e }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readLines := func(want ...string) {
+		t.Helper()
+		for _, w := range want {
+			select {
+			case line := <-tailer.Lines():
+				if line.Text != w {
+					t.Errorf("got %q, want %q", line.Text, w)
+				}
+			case <-ctx.Done():
+				t.Fatalf("timed out waiting for %q", w)
+			}
+		}
+	}
+	readLines("one", "two")
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-events:
+		if e.Type != EventReopened {
+			t.Errorf("got event %v, want %v", e.Type, EventReopened)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the reopened event")
+	}
+	readLines("one", "two")
+
+	cancel()
+	<-tailer.Done()
+}
@@ -0,0 +1,74 @@
+package tailf
+
+import "fmt"
+
+// Reset points the tailer at path, keeping its [Tailer.Lines] and
+// [Tailer.Done] channels, as a viewer switching to another file would.
+// The new file is opened according to the tailer's options with opts
+// applied on top, so by default following starts at its end; pass
+// [WithFromStart] to read it whole. [WithNotify], [WithManualPoll],
+// [WithReplaySignal], [WithScheduler], [WithSpillToDisk] and
+// [WithThroughputWindow] only take effect when the tailer starts and
+// cannot be changed by Reset.
+//
+// Lines of the old file already buffered in the Lines channel, or
+// spilled to disk, may still be received before those of the new one.
+// On success the old handle is closed and an [EventSwitched] event is
+// emitted. If path cannot be opened, the tailer carries on with the old
+// file and the error is returned. It returns [ErrStopped] if the tailer
+// is no longer running.
+func (t *Tailer) Reset(path string, opts ...Option) error {
+	return t.do(func(s *tailState) error {
+		return s.reset(path, opts)
+	})
+}
+
+func (s *tailState) reset(path string, opts []Option) error {
+	o := s.o
+	for _, opt := range opts {
+		opt(&o)
+	}
+	// Keep what was set up when the tailer started.
+	o.notify, o.notifyChans, o.manualPoll = s.o.notify, s.o.notifyChans, s.o.manualPoll
+	o.replaySignal, o.scheduler = s.o.replaySignal, s.o.scheduler
+	o.spill, o.spillDir, o.spillMax = s.o.spill, s.o.spillDir, s.o.spillMax
+	o.throughputWindow = s.o.throughputWindow
+	if _, ok := o.framer.(lineFramer); ok {
+		o.framer = lineFramer{strict: o.strictCRLF, delims: o.delimiters}
+	}
+	if o.gzip {
+		o.startPercent, o.initialBytes = -1, 0
+	}
+
+	path = resolvePath(path, o)
+	file, _, info, torn, err := openFile(path, o)
+	if err != nil {
+		return fmt.Errorf("tailf: %w", err)
+	}
+
+	old := s.path
+	s.file.Close()
+	s.o = o
+	s.file = file
+	s.gz = nil
+	s.scan.r = s.newReader(file)
+	s.scan.torn = torn
+	s.primary = path
+	s.path = path
+	s.identify(info)
+	s.indexPath()
+	s.pending = 0
+	s.stuckPolls = 0
+	s.lastSize = 0
+	s.justRotated = false
+	s.setAtEOF(false)
+	s.before = newLineRing(o.contextBefore)
+	s.afterLeft = 0
+	s.epoch++
+	s.generation++
+	s.t.setInitialSize(file)
+	s.markStart()
+
+	s.emitSwitched(old)
+	return nil
+}
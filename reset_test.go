@@ -0,0 +1,101 @@
+package tailf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReset(t *testing.T) {
+	tmp := t.TempDir()
+	first := filepath.Join(tmp, "first.log")
+	second := filepath.Join(tmp, "second.log")
+
+	if err := os.WriteFile(first, []byte("first 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(second, []byte("second 1\nsecond 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	events := make(chan Event, 4)
+	tailer, err := Follow(ctx, first,
+		WithFromStart(true),
+		WithPollInterval(10*time.Millisecond),
+		WithEventHandler(func(e Event) { events <- e }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readLines := func(want ...string) {
+		t.Helper()
+		for _, w := range want {
+			select {
+			case line := <-tailer.Lines():
+				if line.Text != w {
+					t.Errorf("got %q, want %q", line.Text, w)
+				}
+			case <-ctx.Done():
+				t.Fatalf("timed out waiting for %q", w)
+			}
+		}
+	}
+	readLines("first 1")
+
+	if err := tailer.Reset(filepath.Join(tmp, "missing.log")); err == nil {
+		t.Error("Reset to a missing path succeeded")
+	}
+
+	if err := tailer.Reset(second); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-events:
+		if e.Type != EventSwitched || e.Path != second || e.OldPath != first {
+			t.Errorf("got event %+v, want switch from %s to %s", e, first, second)
+		}
+	default:
+		t.Error("no switched event")
+	}
+
+	// Options given to Follow still apply, so the new file is read
+	// from the start; the old one is no longer followed.
+	readLines("second 1", "second 2")
+	f, err := os.OpenFile(first, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("first 2\n")
+	f.Close()
+	f, err = os.OpenFile(second, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("second 3\n")
+	f.Close()
+	readLines("second 3")
+
+	// New options apply on top of the current ones.
+	if err := tailer.Reset(first, WithFromStart(false)); err != nil {
+		t.Fatal(err)
+	}
+	f, err = os.OpenFile(first, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("first 3\n")
+	f.Close()
+	readLines("first 3")
+
+	cancel()
+	<-tailer.Done()
+	if err := tailer.Reset(second); err != ErrStopped {
+		t.Errorf("Reset after stop = %v, want ErrStopped", err)
+	}
+}
@@ -0,0 +1,33 @@
+package tailf
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// defaultRotationIndex derives the rotation generation of path from a
+// numeric suffix, as in "app.log.1" or "app.log.2.gz", ignoring a ".gz"
+// extension. A path without one, normally the live file, has index 0.
+func defaultRotationIndex(path string) int {
+	name := strings.TrimSuffix(filepath.Base(path), ".gz")
+	i := strings.LastIndexByte(name, '.')
+	if i < 0 {
+		return 0
+	}
+	n, err := strconv.Atoi(name[i+1:])
+	if err != nil || n < 0 || name[i+1] == '+' {
+		return 0
+	}
+	return n
+}
+
+// indexPath records the rotation index of the current path, which lines
+// read from it carry in [Line].RotationIndex.
+func (s *tailState) indexPath() {
+	index := defaultRotationIndex
+	if s.o.rotationIndex != nil {
+		index = s.o.rotationIndex
+	}
+	s.scan.rotationIndex = index(s.path)
+}
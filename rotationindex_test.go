@@ -0,0 +1,68 @@
+package tailf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaultRotationIndex(t *testing.T) {
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/var/log/app.log", 0},
+		{"/var/log/app.log.1", 1},
+		{"/var/log/app.log.12", 12},
+		{"/var/log/app.log.2.gz", 2},
+		{"/var/log/app.log.gz", 0},
+		{"/var/log/app.log.+3", 0},
+		{"/var/log/app.log.", 0},
+		{"/var/log.1/app", 0},
+		{"app", 0},
+	}
+	for _, tt := range tests {
+		if got := defaultRotationIndex(tt.path); got != tt.want {
+			t.Errorf("defaultRotationIndex(%q) = %d, want %d", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestReadAllRotationIndex(t *testing.T) {
+	tmp := t.TempDir()
+	rotated := filepath.Join(tmp, "app.log.3")
+	custom := filepath.Join(tmp, "app-gen7.log")
+	for _, path := range []string{rotated, custom} {
+		if err := os.WriteFile(path, []byte("line\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	lines, err := ReadAll(ctx, rotated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 1 || lines[0].RotationIndex != 3 {
+		t.Errorf("got %+v, want one line with RotationIndex 3", lines)
+	}
+
+	gen := func(path string) int {
+		if strings.Contains(path, "gen7") {
+			return 7
+		}
+		return 0
+	}
+	lines, err = ReadAll(ctx, custom, WithRotationIndexFunc(gen))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 1 || lines[0].RotationIndex != 7 {
+		t.Errorf("got %+v, want one line with RotationIndex 7", lines)
+	}
+}
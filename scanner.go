@@ -0,0 +1,173 @@
+package tailf
+
+import (
+	"bufio"
+	"io"
+	"time"
+)
+
+// lineScanner turns the records of a file into Lines. It frames records
+// with the configured [Framer], capturing the terminator of lines framed
+// by the default one, skips empty records and the torn fragment left by
+// a start inside a line (see [WithAlignToLine]), and builds each Line
+// through the tab expansion, transform and level parsing stages.
+//
+// Incomplete records are not buffered: at EOF, Scan reports how many
+// bytes of one it consumed, and the caller rewinds over them so that the
+// record is framed again from its first byte once it is complete.
+type lineScanner struct {
+	r *bufio.Reader
+	o *options
+
+	// torn is set while the first record read is a fragment of a line
+	// that started before the starting position. It is skipped, or
+	// delivered as a partial line if [WithAlignToLine] is off.
+	torn bool
+
+	// n is the number of bytes of complete records consumed by the last
+	// call to Scan, including skipped ones.
+	n int
+
+	// last is the length of the record of the line returned by the last
+	// call to Scan.
+	last int
+
+	// pending and partial are the length and payload of the incomplete
+	// record at which the last call to Scan reached EOF.
+	pending int
+	partial []byte
+
+	// rotationIndex is the [Line].RotationIndex of the current file.
+	rotationIndex int
+
+	// header is the length of the [WithSkipHeaderBytes] header still to
+	// be read before the first record.
+	header int64
+}
+
+// Scan reads the next line. It returns false at the end of the data
+// available so far, and an error if the framer failed, which is fatal
+// unless it is a read timeout.
+func (sc *lineScanner) Scan() (Line, bool, error) {
+	sc.n, sc.pending, sc.partial = 0, 0, nil
+	if sc.header > 0 {
+		if ok, err := sc.skipHeader(); !ok || err != nil {
+			return Line{}, false, err
+		}
+	}
+	for {
+		n, payload, term, err := sc.frame()
+		if err == io.EOF {
+			sc.pending, sc.partial = n, payload
+			return Line{}, false, nil
+		}
+		if err != nil {
+			// The caller may retry after a timeout, so account for the
+			// bytes consumed as at EOF.
+			sc.pending, sc.partial = n, payload
+			return Line{}, false, err
+		}
+
+		sc.n += n
+		sc.last = n
+		payload = sc.stripPrefix(payload)
+		if len(payload) == 0 || sc.skipping() {
+			sc.torn = false
+			continue
+		}
+		l := sc.line(payload, term)
+		l.Partial, sc.torn = sc.torn, false
+		return l, true, nil
+	}
+}
+
+// skipping reports whether the next record is a torn fragment that is to
+// be skipped.
+func (sc *lineScanner) skipping() bool {
+	return sc.torn && sc.o.alignToLine
+}
+
+// skipHeader reads the [WithSkipHeaderBytes] header and hands it to the
+// [WithOnHeader] callback. It returns false if the header is not
+// complete yet, having consumed what there is of it as pending.
+func (sc *lineScanner) skipHeader() (bool, error) {
+	buf := make([]byte, sc.header)
+	n, err := io.ReadFull(sc.r, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		sc.pending = n
+		return false, nil
+	}
+	if err != nil {
+		sc.pending = n
+		return false, err
+	}
+	sc.n += n
+	sc.header = 0
+	if sc.o.onHeader != nil {
+		sc.o.onHeader(buf)
+	}
+	return true, nil
+}
+
+// frame reads the next record with the configured framer. Only the
+// default line framer reports a terminator.
+func (sc *lineScanner) frame() (int, []byte, []byte, error) {
+	if f, ok := sc.o.framer.(lineFramer); ok {
+		return f.frame(sc.r)
+	}
+	n, payload, err := sc.o.framer.Frame(sc.r)
+	return n, payload, nil, err
+}
+
+// stripPrefix removes the [WithStripPrefix] prefix from a record
+// payload. A payload no longer than the prefix becomes empty.
+func (sc *lineScanner) stripPrefix(payload []byte) []byte {
+	n := sc.o.stripPrefix
+	if n <= 0 {
+		return payload
+	}
+	if len(payload) <= n {
+		return payload[:0]
+	}
+	return payload[n:]
+}
+
+// partialPayload returns the payload of the incomplete record at which
+// Scan last reached EOF, ready to be delivered as a partial line: without
+// the [WithStripPrefix] prefix, and without the '\r' of a line ending
+// that is only half written.
+func (sc *lineScanner) partialPayload() []byte {
+	payload := sc.stripPrefix(sc.partial)
+	if f, ok := sc.o.framer.(lineFramer); ok {
+		payload = f.trimPartial(payload)
+	}
+	return payload
+}
+
+// line builds the Line for a record payload. The text goes through tab
+// expansion and then the user's transform before the level parser sees
+// it; the decorator sees the finished line.
+func (sc *lineScanner) line(payload, term []byte) Line {
+	l := Line{
+		Text:          string(payload),
+		Terminator:    string(term),
+		Time:          time.Now(),
+		RotationIndex: sc.rotationIndex,
+	}
+	if _, ok := sc.o.framer.(fixedFramer); ok {
+		l.Raw = payload
+	}
+	if sc.o.tabWidth > 0 {
+		l.Text = expandTabs(l.Text, sc.o.tabWidth)
+	}
+	if sc.o.transform != nil {
+		l.Text = sc.o.transform(l.Text)
+	}
+	if sc.o.levelParser != nil {
+		l.Level, _ = sc.o.levelParser(l.Text)
+	}
+	if sc.o.decorate != nil {
+		sc.o.decorate(&l)
+	}
+	return l
+}
@@ -0,0 +1,71 @@
+package tailf
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLineScanner(t *testing.T) {
+	o := defaults()
+	o.framer = lineFramer{}
+	o.transform = strings.ToUpper
+	sc := lineScanner{
+		r:    bufio.NewReader(strings.NewReader("rn fragment\n\nfirst\r\nsecond\nthi")),
+		o:    &o,
+		torn: true,
+	}
+
+	// The torn fragment and the empty line are consumed but skipped.
+	line, ok, err := sc.Scan()
+	if err != nil || !ok {
+		t.Fatalf("Scan = %v, %v", ok, err)
+	}
+	if line.Text != "FIRST" || line.Terminator != "\r\n" || sc.n != 20 {
+		t.Errorf("got %q+%q after %d bytes, want %q+%q after 20", line.Text, line.Terminator, sc.n, "FIRST", "\r\n")
+	}
+
+	if line, ok, _ = sc.Scan(); !ok || line.Text != "SECOND" {
+		t.Errorf("got %q, %v; want %q", line.Text, ok, "SECOND")
+	}
+
+	if _, ok, err = sc.Scan(); ok || err != nil {
+		t.Fatalf("Scan at EOF = %v, %v", ok, err)
+	}
+	if sc.n != 0 || sc.pending != 3 || string(sc.partial) != "thi" {
+		t.Errorf("at EOF: n = %d, pending = %d, partial = %q", sc.n, sc.pending, sc.partial)
+	}
+}
+
+func TestReadAllStripPrefix(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	data := "0001 first\n02\n0003 \n0004 second\n0005 last"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// "02" is shorter than the prefix and "0003 " exactly as long, so
+	// both become empty and are skipped.
+	lines, err := ReadAll(ctx, path, WithStripPrefix(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"first", "second", "last"}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d", len(lines), len(want))
+	}
+	for i, w := range want {
+		if lines[i].Text != w {
+			t.Errorf("line %d: got %q, want %q", i, lines[i].Text, w)
+		}
+	}
+}
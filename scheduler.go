@@ -0,0 +1,82 @@
+package tailf
+
+import (
+	"sync"
+	"time"
+)
+
+// Scheduler drives the polling of many tailers from a single ticker, for
+// programs that follow hundreds of files. A tailer created with
+// [WithScheduler] checks its file on the scheduler's ticks instead of
+// arming a timer of its own every time it waits at the end of the file.
+// Create one with [NewScheduler] and stop it with [Scheduler.Stop] once
+// its tailers are done.
+type Scheduler struct {
+	mu     sync.Mutex
+	ticks  map[chan struct{}]struct{}
+	ticker *time.Ticker
+	stop   chan struct{}
+	once   sync.Once
+}
+
+// NewScheduler returns a Scheduler that ticks every interval, raised to
+// 1ms if shorter.
+func NewScheduler(interval time.Duration) *Scheduler {
+	s := &Scheduler{
+		ticks:  make(map[chan struct{}]struct{}),
+		ticker: time.NewTicker(max(interval, minPollInterval)),
+		stop:   make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Stop stops the ticker. Tailers still registered no longer poll, and
+// only wake up for notifications and commands.
+func (s *Scheduler) Stop() {
+	s.once.Do(func() {
+		s.ticker.Stop()
+		close(s.stop)
+	})
+}
+
+// Len returns the number of tailers registered with the scheduler.
+func (s *Scheduler) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.ticks)
+}
+
+// run wakes every registered tailer on each tick. A tailer that has not
+// taken the previous tick yet is not sent another.
+func (s *Scheduler) run() {
+	for {
+		select {
+		case <-s.ticker.C:
+		case <-s.stop:
+			return
+		}
+		s.mu.Lock()
+		for ch := range s.ticks {
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// register returns a channel that receives the scheduler's ticks, and a
+// function that stops them.
+func (s *Scheduler) register() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	s.mu.Lock()
+	s.ticks[ch] = struct{}{}
+	s.mu.Unlock()
+	return ch, func() {
+		s.mu.Lock()
+		delete(s.ticks, ch)
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,113 @@
+package tailf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFollowScheduler(t *testing.T) {
+	tmp := t.TempDir()
+	sched := NewScheduler(10 * time.Millisecond)
+	defer sched.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The poll interval would never wake the tailers; the ticks do.
+	var paths []string
+	var tailers []*Tailer
+	for i := range 3 {
+		path := filepath.Join(tmp, fmt.Sprintf("%d.log", i))
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		tailer, err := Follow(ctx, path, WithPollInterval(time.Hour), WithScheduler(sched))
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+		tailers = append(tailers, tailer)
+	}
+	if n := sched.Len(); n != 3 {
+		t.Errorf("got %d registered tailers, want 3", n)
+	}
+
+	for i, tailer := range tailers {
+		<-tailer.CaughtUp()
+		appendLine(t, paths[i], "hello")
+	}
+	for _, tailer := range tailers {
+		if got := nextLine(ctx, t, tailer); got != "hello" {
+			t.Errorf("got %q, want %q", got, "hello")
+		}
+	}
+
+	cancel()
+	for _, tailer := range tailers {
+		<-tailer.Done()
+	}
+	if n := sched.Len(); n != 0 {
+		t.Errorf("got %d registered tailers after stopping, want 0", n)
+	}
+}
+
+// BenchmarkManyTailers measures a round of one appended line received
+// from each of 500 idle tailers, each polling on its own timer or on a
+// shared scheduler.
+func BenchmarkManyTailers(b *testing.B) {
+	const tailers = 500
+	const interval = 5 * time.Millisecond
+
+	for _, shared := range []bool{false, true} {
+		b.Run(fmt.Sprintf("scheduler=%v", shared), func(b *testing.B) {
+			tmp := b.TempDir()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			opts := []Option{WithPollInterval(interval)}
+			if shared {
+				sched := NewScheduler(interval)
+				defer sched.Stop()
+				opts = append(opts, WithScheduler(sched))
+			}
+
+			files := make([]*os.File, tailers)
+			ts := make([]*Tailer, tailers)
+			for i := range tailers {
+				path := filepath.Join(tmp, fmt.Sprintf("%d.log", i))
+				f, err := os.Create(path)
+				if err != nil {
+					b.Fatal(err)
+				}
+				defer f.Close()
+				files[i] = f
+				if ts[i], err = Follow(ctx, path, opts...); err != nil {
+					b.Fatal(err)
+				}
+			}
+			for _, t := range ts {
+				<-t.CaughtUp()
+			}
+
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				for _, f := range files {
+					fmt.Fprintln(f, "line")
+				}
+				for _, t := range ts {
+					<-t.Lines()
+				}
+			}
+			b.StopTimer()
+
+			cancel()
+			for _, t := range ts {
+				<-t.Done()
+			}
+		})
+	}
+}
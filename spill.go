@@ -0,0 +1,240 @@
+package tailf
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// spillQueue is an on-disk FIFO of lines that did not fit in the Lines
+// channel, as enabled by [WithSpillToDisk]. Each record is a 4-byte
+// big-endian length followed by the line encoded as JSON. The file is
+// created on first use, truncated whenever the queue empties, and
+// removed when the tailer stops.
+type spillQueue struct {
+	dir      string
+	maxBytes int64
+
+	// clock is the reference that spilled line times are stored
+	// relative to; see spilledLine.
+	clock time.Time
+
+	file   *os.File
+	rd, wr int64 // offsets of the next record to read and to write
+	n      int   // number of queued lines
+
+	// at holds where each queued line was read, oldest first, for
+	// [Tailer.Position].
+	at []linePos
+
+	head    Line // decoded line at rd, valid while headLen > 0
+	headLen int64
+}
+
+// spilledLine is the record of a spilled line. JSON drops the monotonic
+// clock reading of the line's time, so it is also stored as Since, an
+// offset from the queue's clock, from which a time with a monotonic
+// reading is rebuilt. Its wall clock reading is rebuilt too, and so
+// follows the monotonic clock rather than any adjustment of the wall
+// clock in between.
+type spilledLine struct {
+	Line
+	Since time.Duration `json:"since"`
+}
+
+// push appends l, read at at, to the queue. It reports false if that
+// would exceed the size limit or the spill file cannot be written.
+func (q *spillQueue) push(l Line, at linePos) bool {
+	b, err := json.Marshal(spilledLine{Line: l, Since: l.Time.Sub(q.clock)})
+	if err != nil {
+		return false
+	}
+	rec := binary.BigEndian.AppendUint32(nil, uint32(len(b)))
+	rec = append(rec, b...)
+	if q.maxBytes > 0 && q.wr-q.rd+int64(len(rec)) > q.maxBytes {
+		return false
+	}
+
+	if q.file == nil {
+		f, err := os.CreateTemp(q.dir, "tailf-spill-*")
+		if err != nil {
+			return false
+		}
+		q.file = f
+	}
+	if _, err := q.file.WriteAt(rec, q.wr); err != nil {
+		return false
+	}
+	q.wr += int64(len(rec))
+	q.n++
+	q.at = append(q.at, at)
+	return true
+}
+
+// peek returns the oldest queued line. A record that cannot be read
+// back empties the queue, since the ones after it cannot be found.
+func (q *spillQueue) peek() (Line, bool) {
+	if q.n == 0 {
+		return Line{}, false
+	}
+	if q.headLen > 0 {
+		return q.head, true
+	}
+
+	var header [4]byte
+	if _, err := q.file.ReadAt(header[:], q.rd); err != nil {
+		q.reset()
+		return Line{}, false
+	}
+	b := make([]byte, binary.BigEndian.Uint32(header[:]))
+	if _, err := q.file.ReadAt(b, q.rd+4); err != nil {
+		q.reset()
+		return Line{}, false
+	}
+	var rec spilledLine
+	if err := json.Unmarshal(b, &rec); err != nil {
+		q.reset()
+		return Line{}, false
+	}
+	l := rec.Line
+	if !l.Time.IsZero() {
+		l.Time = q.clock.Add(rec.Since)
+	}
+	q.head, q.headLen = l, int64(4+len(b))
+	return l, true
+}
+
+// pop removes the line last returned by peek.
+func (q *spillQueue) pop() {
+	q.rd += q.headLen
+	q.head, q.headLen = Line{}, 0
+	q.n--
+	q.at = q.at[1:]
+	if q.n == 0 {
+		q.reset()
+	}
+}
+
+// reset discards every queued line.
+func (q *spillQueue) reset() {
+	q.rd, q.wr, q.n = 0, 0, 0
+	q.at = nil
+	q.head, q.headLen = Line{}, 0
+	if q.file != nil {
+		q.file.Truncate(0)
+	}
+}
+
+// size returns the number of bytes held in the spill file.
+func (q *spillQueue) size() int64 {
+	return q.wr - q.rd
+}
+
+func (q *spillQueue) close() {
+	if q.file == nil {
+		return
+	}
+	q.file.Close()
+	os.Remove(q.file.Name())
+	q.file = nil
+}
+
+// spillLine queues l, read at at, on disk if lines are already queued there, to keep
+// them in order, or if the Lines channel is full. It first moves as many
+// queued lines to the channel as fit. It reports whether l was queued.
+func (s *tailState) spillLine(l Line, at linePos) bool {
+	s.unspill()
+	if s.spill.n == 0 && len(s.t.lines) < cap(s.t.lines) {
+		return false
+	}
+	if !s.spill.push(l, at) {
+		return false
+	}
+	s.spillStats()
+	return true
+}
+
+// unspill moves queued lines to the Lines channel until it is full.
+func (s *tailState) unspill() {
+	for {
+		l, ok := s.peekSpilled()
+		if !ok {
+			return
+		}
+		select {
+		case s.t.lines <- l:
+			s.popSent(l)
+		default:
+			return
+		}
+	}
+}
+
+// sendSpilled blocks until the oldest queued line is sent on the Lines
+// channel, serving commands meanwhile. It returns false if ctx was
+// cancelled first.
+func (s *tailState) sendSpilled(ctx context.Context) bool {
+	out, l := s.spilled()
+	select {
+	case out <- l:
+		s.sentSpilled(l)
+	case c := <-s.t.cmds:
+		s.run(c)
+	case <-ctx.Done():
+		return false
+	}
+	return true
+}
+
+// spilled returns the channel to send the oldest queued line on, and
+// that line, or a nil channel if nothing is queued, for use in a select.
+func (s *tailState) spilled() (chan<- Line, Line) {
+	if s.spill == nil {
+		return nil, Line{}
+	}
+	l, ok := s.peekSpilled()
+	if !ok {
+		return nil, Line{}
+	}
+	return s.t.lines, l
+}
+
+// sentSpilled completes the send of a line returned by spilled.
+func (s *tailState) sentSpilled(l Line) {
+	s.popSent(l)
+	s.unspill()
+}
+
+// peekSpilled returns the oldest queued line, updating the stats if
+// the queue had to be discarded because it could not be read back.
+func (s *tailState) peekSpilled() (Line, bool) {
+	queued := s.spill.n
+	l, ok := s.spill.peek()
+	if !ok && queued > 0 {
+		s.spillStats()
+	}
+	return l, ok
+}
+
+// popSent removes l, just sent, from the queue. The line is counted as
+// sent and as no longer spilled together, so that no Stats snapshot
+// counts it twice or not at all.
+func (s *tailState) popSent(l Line) {
+	s.spill.pop()
+	n, size := int64(s.spill.n), s.spill.size()
+	s.t.mu.Lock()
+	defer s.t.mu.Unlock()
+	s.t.recordSentLocked(l)
+	s.t.stats.Spilled = n
+	s.t.stats.SpilledBytes = size
+}
+
+func (s *tailState) spillStats() {
+	n, size := int64(s.spill.n), s.spill.size()
+	s.t.updateStats(func(st *Stats) {
+		st.Spilled = n
+		st.SpilledBytes = size
+	})
+}
@@ -0,0 +1,160 @@
+package tailf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeNumbered(t *testing.T, path string, n int) {
+	t.Helper()
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSpillToDisk(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+	spillDir := filepath.Join(tmp, "spill")
+	if err := os.Mkdir(spillDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeNumbered(t, path, 200)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFromStart(true), WithSpillToDisk(spillDir, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nobody is receiving, yet the tailer reads the whole backlog.
+	select {
+	case <-tailer.CaughtUp():
+	case <-ctx.Done():
+		t.Fatal("timed out waiting to catch up")
+	}
+	st := tailer.Stats()
+	if want := int64(200 - cap(tailer.lines)); st.Spilled != want || st.SpilledBytes == 0 {
+		t.Errorf("spilled %d lines, %d bytes; want %d lines", st.Spilled, st.SpilledBytes, want)
+	}
+
+	for i := 0; i < 200; i++ {
+		select {
+		case line := <-tailer.Lines():
+			if want := fmt.Sprintf("line %d", i); line.Text != want {
+				t.Fatalf("got %q, want %q", line.Text, want)
+			}
+		case <-ctx.Done():
+			t.Fatalf("timed out after %d lines", i)
+		}
+	}
+	// A line is counted just after it is sent, so the last count may
+	// lag its receipt; once it is in, nothing may remain spilled.
+	st = tailer.Stats()
+	for st.Lines != 200 && ctx.Err() == nil {
+		time.Sleep(time.Millisecond)
+		st = tailer.Stats()
+	}
+	if st.Spilled != 0 || st.SpilledBytes != 0 || st.Lines != 200 {
+		t.Errorf("after draining: %+v", st)
+	}
+
+	cancel()
+	<-tailer.Done()
+
+	entries, err := os.ReadDir(spillDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("spill directory not cleaned up: %v", entries)
+	}
+}
+
+func TestSpillToDiskPosition(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+	writeNumbered(t, path, 200)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFromStart(true), WithSpillToDisk(tmp, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-tailer.CaughtUp():
+	case <-ctx.Done():
+		t.Fatal("timed out waiting to catch up")
+	}
+
+	// The lines queued on disk have not been delivered, so a resumed
+	// tailer starts with the first of them.
+	tok, err := tailer.Position()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	<-tailer.Done()
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resumed, err := Follow(ctx, path, WithResumeToken(tok, ResumeError))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprintf("line %d", lineBuffer)
+	if got := nextLine(ctx, t, resumed); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	cancel()
+	<-resumed.Done()
+}
+
+func TestSpillToDiskFull(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+	writeNumbered(t, path, 200)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFromStart(true),
+		WithSpillToDisk(tmp, 1024), WithOverflowPolicy(OverflowDrop))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-tailer.CaughtUp()
+
+	st := tailer.Stats()
+	if st.SpilledBytes > 1024 || st.Dropped == 0 {
+		t.Errorf("spilled %d bytes, dropped %d; want at most 1024 bytes and some dropped", st.SpilledBytes, st.Dropped)
+	}
+
+	// Whatever was kept arrives in order.
+	last := -1
+	for i := int64(0); i < st.Lines+st.Spilled; i++ {
+		line := <-tailer.Lines()
+		var n int
+		fmt.Sscanf(line.Text, "line %d", &n)
+		if n <= last {
+			t.Fatalf("got line %d after line %d", n, last)
+		}
+		last = n
+	}
+
+	cancel()
+	<-tailer.Done()
+}
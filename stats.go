@@ -0,0 +1,222 @@
+package tailf
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+// Stats is a snapshot of counters describing a tailer's activity.
+type Stats struct {
+	// Lines is the number of lines delivered on the Lines channel,
+	// after filtering.
+	Lines int64
+
+	// BytesRead is the number of bytes consumed from the file, including
+	// line terminators and skipped empty lines.
+	BytesRead int64
+
+	// BytesPerSec is the current read throughput, an exponentially
+	// weighted moving average over the window set by
+	// [WithThroughputWindow]. It decays towards zero while idle.
+	BytesPerSec float64
+
+	// Truncations is the number of times the file was truncated in place.
+	Truncations int64
+
+	// Rotations is the number of times the file at the path was replaced
+	// by a new one.
+	Rotations int64
+
+	// LastTruncation and LastRotation are when the most recent truncation
+	// and rotation were detected, or zero if there has been none.
+	LastTruncation time.Time
+	LastRotation   time.Time
+
+	// Reopens is the number of times a stuck handle was reopened.
+	Reopens int64
+
+	// Dropped is the number of lines discarded under [OverflowDrop].
+	Dropped int64
+
+	// DroppedOnRotation is the number of bytes left unread in rotated
+	// files that kept growing past [WithRotationDrainTimeout].
+	DroppedOnRotation int64
+
+	// Duplicates is the number of lines skipped by [WithDedupRecent].
+	Duplicates int64
+
+	// Spilled and SpilledBytes are the number of lines, and the bytes
+	// they take, currently queued on disk by [WithSpillToDisk].
+	Spilled      int64
+	SpilledBytes int64
+
+	// TeeErrors is the number of failed writes to the [WithTee] writer
+	// that were ignored.
+	TeeErrors int64
+
+	// BufferedBytes approximates the memory held by the tailer for lines
+	// not yet received: the text of lines in the Lines channel, lines
+	// held back as filter context, and any incomplete trailing line.
+	BufferedBytes int64
+
+	// DeliveryLatency is how long the oldest line still in the Lines
+	// channel has waited since it was read ([Line].Time), or 0 if the
+	// channel is empty. It keeps growing while the consumer does not
+	// receive, so it tells a stalled or slow consumer from a writer that
+	// simply has nothing new.
+	DeliveryLatency time.Duration
+
+	// ChannelFill is the fraction of the Lines channel's capacity taken,
+	// from 0 to 1, as a moving average over the last few dozen lines
+	// sent. Near 1 the consumer is the bottleneck; near 0 with a low
+	// BytesPerSec, the tailer is waiting for the writer.
+	ChannelFill float64
+
+	// Started is when the tailer started. Stopped is when it stopped, or
+	// zero while it runs, and StopReason tells why.
+	Started    time.Time
+	Stopped    time.Time
+	StopReason StopReason
+}
+
+// StopReason tells why a tailer stopped.
+type StopReason int
+
+const (
+	// NotStopped is the zero value, for a tailer that is still running.
+	NotStopped StopReason = iota
+
+	// StopCancelled means the context passed to [Follow] was cancelled.
+	StopCancelled
+
+	// StopEOF means the tailer reached the end of its input, as with
+	// [WithStopAtEOF], including when it withheld an incomplete last
+	// line with [ErrTrailingPartial].
+	StopEOF
+
+	// StopError means a fatal error, the one [Tailer.Err] returns,
+	// stopped the tailer.
+	StopError
+)
+
+// String returns the name of the stop reason.
+func (r StopReason) String() string {
+	switch r {
+	case NotStopped:
+		return "not-stopped"
+	case StopCancelled:
+		return "cancelled"
+	case StopEOF:
+		return "eof"
+	case StopError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// FinalStats returns the statistics of the tailer as of when it stopped,
+// with Stopped and StopReason set. Once [Tailer.Done] is closed the
+// result no longer changes and is safe to read from any goroutine; before
+// that, FinalStats returns the zero Stats. It is the same snapshot as the
+// [LifecycleStopped] event carries.
+func (t *Tailer) FinalStats() Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.final
+}
+
+// recordStop records why and when the tailer stopped, and takes the
+// snapshot that [Tailer.FinalStats] returns. [ErrTrailingPartial] is not
+// fatal, so a session that ends with it stopped at the end of its input.
+func (t *Tailer) recordStop(ctx context.Context) Stats {
+	reason := StopEOF
+	switch err := t.Err(); {
+	case err != nil && !errors.Is(err, ErrTrailingPartial):
+		reason = StopError
+	case ctx.Err() != nil:
+		reason = StopCancelled
+	}
+	t.updateStats(func(st *Stats) {
+		st.Stopped = time.Now()
+		st.StopReason = reason
+	})
+	final := t.Stats()
+	t.mu.Lock()
+	t.final = final
+	t.mu.Unlock()
+	return final
+}
+
+// Stats returns a snapshot of the tailer's counters. It is safe to call
+// concurrently with tailing.
+func (t *Tailer) Stats() Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	st := t.stats
+	st.BytesPerSec = t.rate.value(time.Now())
+	st.BufferedBytes = t.sent.buffered(len(t.lines)) + t.held
+	if read := t.sent.oldest(len(t.lines)); !read.IsZero() {
+		st.DeliveryLatency = max(time.Since(read), 0)
+	}
+	st.ChannelFill = t.fill.value
+	return st
+}
+
+func (t *Tailer) updateStats(fn func(*Stats)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fn(&t.stats)
+}
+
+// recordRead accounts for n bytes consumed from the file.
+func (t *Tailer) recordRead(n int) {
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats.BytesRead += int64(n)
+	t.rate.add(n, now)
+}
+
+// fillGauge is the moving average behind Stats().ChannelFill. Each line
+// sent moves it a sixteenth of the way towards the fill it found.
+type fillGauge struct {
+	value   float64
+	started bool
+}
+
+func (g *fillGauge) add(fill float64) {
+	if !g.started {
+		g.value, g.started = fill, true
+		return
+	}
+	g.value += (fill - g.value) / 16
+}
+
+// throughput is an exponentially weighted moving average of a byte rate.
+// Each read adds n/window to the rate, and the rate decays by a factor
+// of e every window, so a steady flow of r bytes per second converges
+// to a rate of r.
+type throughput struct {
+	window time.Duration
+	rate   float64
+	at     time.Time
+}
+
+func (r *throughput) add(n int, now time.Time) {
+	if r.window <= 0 {
+		return
+	}
+	r.rate = r.value(now) + float64(n)/r.window.Seconds()
+	r.at = now
+}
+
+func (r *throughput) value(now time.Time) float64 {
+	if r.rate == 0 {
+		return 0
+	}
+	elapsed := now.Sub(r.at)
+	return r.rate * math.Exp(-elapsed.Seconds()/r.window.Seconds())
+}
@@ -0,0 +1,103 @@
+package tailf
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestThroughputConverges(t *testing.T) {
+	r := throughput{window: time.Second}
+	now := time.Unix(0, 0)
+
+	// 1000 bytes every 10ms is 100000 bytes per second.
+	for i := 0; i < 1000; i++ {
+		now = now.Add(10 * time.Millisecond)
+		r.add(1000, now)
+	}
+	if got := r.value(now); math.Abs(got-100000)/100000 > 0.01 {
+		t.Errorf("steady rate = %.0f, want about 100000", got)
+	}
+
+	// After five windows of silence the rate has all but vanished.
+	if got := r.value(now.Add(5 * time.Second)); got > 1000 {
+		t.Errorf("idle rate = %.0f, want < 1000", got)
+	}
+}
+
+func TestStatsCountsDeliveredBytes(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("one\n\ntwo\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFromStart(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-tailer.Lines():
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+	}
+	<-tailer.CaughtUp()
+
+	st := tailer.Stats()
+	if st.Lines != 2 || st.BytesRead != 10 {
+		t.Errorf("got %d lines, %d bytes; want 2 lines, 10 bytes", st.Lines, st.BytesRead)
+	}
+	if st.BytesPerSec <= 0 {
+		t.Errorf("BytesPerSec = %v, want > 0", st.BytesPerSec)
+	}
+
+	cancel()
+	<-tailer.Done()
+}
+
+func TestStatsDeliveryLatency(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	writeNumbered(t, path, 2*lineBuffer)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFromStart(true), WithPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A consumer that falls behind leaves the channel full, and the
+	// oldest line in it keeps waiting.
+	for tailer.Pending() < lineBuffer {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(100 * time.Millisecond)
+	slow := tailer.Stats()
+	if slow.DeliveryLatency < 100*time.Millisecond || slow.ChannelFill < 0.5 {
+		t.Errorf("slow consumer: got latency %v, fill %.2f", slow.DeliveryLatency, slow.ChannelFill)
+	}
+	for range 2 * lineBuffer {
+		<-tailer.Lines()
+	}
+
+	// A consumer that keeps up sees both fall back.
+	<-tailer.CaughtUp()
+	for range 2 * lineBuffer {
+		appendLine(t, path, "live")
+		<-tailer.Lines()
+	}
+	fast := tailer.Stats()
+	if fast.DeliveryLatency != 0 || fast.ChannelFill > 0.1 {
+		t.Errorf("fast consumer: got latency %v, fill %.2f", fast.DeliveryLatency, fast.ChannelFill)
+	}
+}
@@ -0,0 +1,125 @@
+package tailf
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrTrailingPartial is reported when a [WithStopAtEOF] session with
+// [WithRequireFinalNewline] ends on an incomplete last line and that
+// line is withheld. It is not fatal: every complete line has been
+// delivered by the time it is reported.
+var ErrTrailingPartial = errors.New("tailf: incomplete last line withheld")
+
+// ReadAll reads the lines of the file at path from the start up to its
+// current end and returns them. It is [Follow] with [WithFromStart] and
+// [WithStopAtEOF], so the last line is included even if the file does
+// not end with a newline. With [WithRequireFinalNewline] such a line is
+// left out and the complete lines are returned with [ErrTrailingPartial].
+// Other options apply as for Follow; in
+// particular [WithFromStart] or [WithStartAtPercent] can pick a later
+// starting point.
+func ReadAll(ctx context.Context, path string, opts ...Option) ([]Line, error) {
+	opts = append([]Option{WithFromStart(true)}, opts...)
+	t, err := Follow(ctx, path, append(opts, WithStopAtEOF(true))...)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []Line
+	for line := range t.Lines() {
+		lines = append(lines, line)
+	}
+	if err := t.Err(); err != nil {
+		return lines, err
+	}
+	return lines, ctx.Err()
+}
+
+// FollowN follows the file at path like [Follow] and returns the next n
+// lines written to it, waiting for as long as ctx allows. If ctx expires
+// or is cancelled first, the lines collected so far are returned with
+// ctx.Err(), so a deadline gives a partial result and an error for which
+// errors.Is(err, context.DeadlineExceeded) holds. If the tailer stops
+// first, the lines are returned with its error, which is nil when a
+// [WithStopAtEOF] session reached the end of the file. Lines injected by
+// [WithInBandMarkers] are not counted or returned.
+//
+// The tailer is stopped and its file closed before FollowN returns.
+// Options apply as for Follow; with [WithFromStart] the lines are
+// counted from the start of the file. If n is zero or negative, FollowN
+// returns nil and a nil error at once, without opening the file.
+func FollowN(ctx context.Context, path string, n int, opts ...Option) ([]Line, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	tailCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	t, err := Follow(tailCtx, path, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		cancel()
+		<-t.Done()
+	}()
+
+	lines := make([]Line, 0, n)
+	for len(lines) < n {
+		select {
+		case line, ok := <-t.Lines():
+			if !ok {
+				if err := t.Err(); err != nil {
+					return lines, err
+				}
+				return lines, ctx.Err()
+			}
+			if line.Marker == NoMarker {
+				lines = append(lines, line)
+			}
+		case <-ctx.Done():
+			return lines, ctx.Err()
+		}
+	}
+	return lines, nil
+}
+
+// finish ends a [WithStopAtEOF] session at the end of the file. An
+// incomplete last line is delivered as a partial line, and lines still
+// spilled to disk are flushed. An incomplete line withheld because of
+// [WithRequireFinalNewline] is reported as [ErrTrailingPartial].
+func (s *tailState) finish(ctx context.Context) {
+	s.stopped = true
+	if !s.flushReorder(ctx) {
+		return
+	}
+
+	_, lines := s.o.framer.(lineFramer)
+	lines = lines && s.scan.header == 0
+	if n := s.scan.pending; lines && n > 0 && !s.scan.skipping() && s.o.requireFinalNewline {
+		s.t.setErr(ErrTrailingPartial)
+	} else if lines && n > 0 && !s.scan.skipping() {
+		s.t.recordRead(n)
+		if payload := s.scan.partialPayload(); len(payload) > 0 {
+			l := s.scan.line(payload, nil)
+			l.Partial = true
+			if !s.deliver(ctx, l) {
+				return
+			}
+		}
+	}
+
+	if !s.caughtUp {
+		s.caughtUp = true
+		if !s.signalCaughtUp(ctx) {
+			return
+		}
+	}
+
+	for s.spill != nil && s.spill.n > 0 {
+		if !s.sendSpilled(ctx) {
+			return
+		}
+	}
+}
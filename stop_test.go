@@ -0,0 +1,176 @@
+package tailf
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadAllFinalLineWithoutNewline(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("first\r\nsecond\nlast line no newline"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	lines, err := ReadAll(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Line{
+		{Text: "first", Terminator: "\r\n"},
+		{Text: "second", Terminator: "\n"},
+		{Text: "last line no newline", Partial: true},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d", len(lines), len(want))
+	}
+	for i, w := range want {
+		l := lines[i]
+		if l.Text != w.Text || l.Terminator != w.Terminator || l.Partial != w.Partial {
+			t.Errorf("line %d: got %q+%q partial=%v, want %q+%q partial=%v",
+				i, l.Text, l.Terminator, l.Partial, w.Text, w.Terminator, w.Partial)
+		}
+	}
+
+	lines, err = ReadAll(ctx, path, WithRequireFinalNewline(true))
+	if !errors.Is(err, ErrTrailingPartial) {
+		t.Errorf("with WithRequireFinalNewline err = %v, want ErrTrailingPartial", err)
+	}
+	if len(lines) != 2 {
+		t.Errorf("with WithRequireFinalNewline got %d lines, want 2", len(lines))
+	}
+}
+
+func TestReadAllFinalLineHalfCRLF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	// A Windows writer caught between the "\r" and the "\n".
+	if err := os.WriteFile(path, []byte("first\r\nlast\r"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	for _, strict := range []bool{false, true} {
+		lines, err := ReadAll(ctx, path, WithStrictCRLF(strict))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(lines) != 2 || lines[1].Text != "last" || !lines[1].Partial {
+			t.Errorf("strict=%v: got %+v, want a partial %q last", strict, lines, "last")
+		}
+	}
+}
+
+func TestReadAllRequireFinalNewlineComplete(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	lines, err := ReadAll(ctx, path, WithRequireFinalNewline(true))
+	if err != nil {
+		t.Fatalf("ReadAll = %v, want nil for a file ending in a newline", err)
+	}
+	if len(lines) != 2 {
+		t.Errorf("got %d lines, want 2", len(lines))
+	}
+
+	partial := filepath.Join(tmp, "partial.log")
+	if err := os.WriteFile(partial, []byte("one\ntwo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := OpenPull(ctx, partial, WithFromStart(true), WithStopAtEOF(true), WithRequireFinalNewline(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if _, err := p.Next(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Next(ctx); !errors.Is(err, ErrTrailingPartial) {
+		t.Errorf("Next at end = %v, want ErrTrailingPartial", err)
+	}
+}
+
+func TestPullStopAtEOF(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("one\ntwo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	p, err := OpenPull(ctx, path, WithFromStart(true), WithStopAtEOF(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	for _, want := range []string{"one", "two"} {
+		line, err := p.Next(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line.Text != want {
+			t.Errorf("got %q, want %q", line.Text, want)
+		}
+	}
+	if _, err := p.Next(ctx); !errors.Is(err, io.EOF) {
+		t.Errorf("Next at end = %v, want io.EOF", err)
+	}
+}
+
+func TestFollowNDeadline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, []byte("a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		if f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644); err == nil {
+			f.WriteString("b\n")
+			f.Close()
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	lines, err := FollowN(ctx, path, 3, WithFromStart(true), WithPollInterval(10*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want context.DeadlineExceeded", err)
+	}
+	if len(lines) != 2 || lines[0].Text != "a" || lines[1].Text != "b" {
+		t.Errorf("got %+v, want a and b", lines)
+	}
+
+	// With enough lines there is no error.
+	lines, err = FollowN(context.Background(), path, 2, WithFromStart(true))
+	if err != nil || len(lines) != 2 {
+		t.Errorf("got %d lines, %v, want 2, nil", len(lines), err)
+	}
+
+	for _, n := range []int{0, -1} {
+		if lines, err := FollowN(context.Background(), path, n); lines != nil || err != nil {
+			t.Errorf("n=%d: got %v, %v, want nil, nil", n, lines, err)
+		}
+	}
+}
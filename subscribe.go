@@ -0,0 +1,119 @@
+package tailf
+
+import (
+	"context"
+	"regexp"
+)
+
+// Subscribe returns a channel that receives a copy of every line sent on
+// [Tailer.Lines] from now on, including in-band markers, without taking
+// lines away from the main consumer. A subscriber must keep up: a line
+// that does not fit in its buffer of the given size is dropped for that
+// subscriber, so that it can never hold up tailing. Call the returned
+// function to unsubscribe; the channel is then closed. It is also closed
+// when the tailer stops.
+func (t *Tailer) Subscribe(buffer int) (<-chan Line, func()) {
+	ch := make(chan Line, buffer)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.subsClosed {
+		close(ch)
+		return ch, func() {}
+	}
+	if t.subs == nil {
+		t.subs = make(map[chan Line]struct{})
+	}
+	t.subs[ch] = struct{}{}
+
+	return ch, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if _, ok := t.subs[ch]; ok {
+			delete(t.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// publish passes l to the subscribers and the waiting [Tailer.WaitMatch]
+// calls. It must be called with t.mu held.
+func (t *Tailer) publish(l Line) {
+	for ch := range t.subs {
+		select {
+		case ch <- l:
+		default:
+		}
+	}
+	if l.Marker != NoMarker {
+		return
+	}
+	for m := range t.matchers {
+		if sub := m.re.FindStringSubmatch(l.Text); sub != nil {
+			m.found <- sub
+			delete(t.matchers, m)
+		}
+	}
+}
+
+// matcher is a [Tailer.WaitMatch] call waiting for a line matching re.
+// The first match is sent on found, which has room for it, and found is
+// closed instead if the tailer stops without one.
+type matcher struct {
+	re    *regexp.Regexp
+	found chan []string
+}
+
+// closeSubscribers closes the channels of all subscribers once the
+// tailer has stopped.
+func (t *Tailer) closeSubscribers() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for ch := range t.subs {
+		close(ch)
+	}
+	for m := range t.matchers {
+		close(m.found)
+	}
+	t.subs = nil
+	t.matchers = nil
+	t.subsClosed = true
+}
+
+// WaitMatch waits for a line matching re and returns the result of
+// re.FindStringSubmatch on it: the whole match followed by the capture
+// groups. It only sees lines sent on [Tailer.Lines] after it was called.
+// Lines are matched as they are sent, so the main consumer still
+// receives every line and no line is missed however fast they come. It
+// returns ctx's error if ctx is done first, and the tailer's error, or
+// [ErrStopped], if the tailer stops first.
+func (t *Tailer) WaitMatch(ctx context.Context, re *regexp.Regexp) ([]string, error) {
+	m := &matcher{re: re, found: make(chan []string, 1)}
+	t.mu.Lock()
+	if t.subsClosed {
+		close(m.found)
+	} else {
+		if t.matchers == nil {
+			t.matchers = make(map[*matcher]struct{})
+		}
+		t.matchers[m] = struct{}{}
+	}
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		delete(t.matchers, m)
+	}()
+
+	select {
+	case sub, ok := <-m.found:
+		if !ok {
+			if err := t.Err(); err != nil {
+				return nil, err
+			}
+			return nil, ErrStopped
+		}
+		return sub, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,180 @@
+package tailf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWaitMatch(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type result struct {
+		m   []string
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		m, err := tailer.WaitMatch(ctx, regexp.MustCompile(`listening on port (\d+)`))
+		done <- result{m, err}
+	}()
+	// Let WaitMatch subscribe before the lines are written.
+	time.Sleep(20 * time.Millisecond)
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("starting\nlistening on port 8080\nready\n")
+	f.Close()
+
+	select {
+	case r := <-done:
+		if r.err != nil {
+			t.Fatal(r.err)
+		}
+		if len(r.m) != 2 || r.m[1] != "8080" {
+			t.Errorf("got %q, want the port 8080 captured", r.m)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the match")
+	}
+
+	// The main consumer still receives every line.
+	for _, want := range []string{"starting", "listening on port 8080", "ready"} {
+		select {
+		case line := <-tailer.Lines():
+			if line.Text != want {
+				t.Errorf("got %q, want %q", line.Text, want)
+			}
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for %q", want)
+		}
+	}
+
+	short, shortCancel := context.WithTimeout(ctx, 30*time.Millisecond)
+	defer shortCancel()
+	if _, err := tailer.WaitMatch(short, regexp.MustCompile("never")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitMatch on timeout = %v, want context.DeadlineExceeded", err)
+	}
+
+	cancel()
+	<-tailer.Done()
+	if _, err := tailer.WaitMatch(context.Background(), regexp.MustCompile("x")); !errors.Is(err, ErrStopped) {
+		t.Errorf("WaitMatch after stop = %v, want ErrStopped", err)
+	}
+}
+
+func TestWaitMatchBurst(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-tailer.CaughtUp()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := tailer.WaitMatch(ctx, regexp.MustCompile(`^ready$`))
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	// Far more lines than a subscriber buffers arrive at once, and the
+	// main consumer takes them as fast as they come.
+	var b strings.Builder
+	for i := 0; i < 20*lineBuffer; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	b.WriteString("ready\n")
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(b.String())
+	f.Close()
+
+	for {
+		select {
+		case <-tailer.Lines():
+			continue
+		case err := <-done:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for the match")
+		}
+		break
+	}
+
+	cancel()
+	<-tailer.Done()
+}
+
+func TestSubscribeUnsubscribe(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub, unsubscribe := tailer.Subscribe(4)
+	if err := os.WriteFile(path, []byte("one\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case line := <-sub:
+		if line.Text != "one" {
+			t.Errorf("got %q, want %q", line.Text, "one")
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the subscribed line")
+	}
+
+	unsubscribe()
+	unsubscribe()
+	if _, ok := <-sub; ok {
+		t.Error("subscription still open after unsubscribing")
+	}
+
+	cancel()
+	<-tailer.Done()
+}
@@ -0,0 +1,98 @@
+package tailf
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// supervise runs the tailing loop, restarting it after a fatal error as
+// configured by [WithSupervise]. It returns the error that finally
+// stopped the tailer, or nil if ctx was cancelled.
+func (s *tailState) supervise(ctx context.Context) error {
+	if !s.replaySuccessor(ctx) {
+		return nil
+	}
+	if s.file == nil && !s.waitForFile(ctx) {
+		return nil
+	}
+	err := tailLoop(ctx, s)
+	for restarts := 0; err != nil && !errors.Is(err, ErrFileTooLarge) && restarts < s.o.maxRestarts; restarts++ {
+		s.announce(LifecycleEvent{Type: LifecycleError, Err: err})
+		if !s.sleep(ctx, s.o.restartDelay) {
+			return nil
+		}
+		if !s.recoverFrom(err) {
+			continue
+		}
+		err = tailLoop(ctx, s)
+	}
+	if err != nil {
+		s.announce(LifecycleEvent{Type: LifecycleError, Err: err})
+	}
+	return err
+}
+
+// sleep waits for d while serving commands. It returns false if ctx was
+// cancelled first.
+func (s *tailState) sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			return true
+		case c := <-s.t.cmds:
+			s.run(c)
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// recoverFrom reopens the path, or under [WithArchivePattern] the file
+// it named if that has been moved, after the fatal error err, at the offset
+// the error occurred, or at the end of the file if that offset is not
+// known or no longer valid. It reports false if the path could not be
+// reopened.
+func (s *tailState) recoverFrom(err error) bool {
+	offset := int64(-1)
+	var te *TailError
+	if errors.As(err, &te) {
+		offset = te.Offset
+	}
+
+	file, info, err := s.openCurrent()
+	if err != nil {
+		return false
+	}
+	if offset < 0 || offset > info.Size() {
+		offset = info.Size()
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return false
+	}
+
+	s.file.Close()
+	s.file = file
+	s.scan.r = s.newReader(file)
+	prev := s.identity()
+	s.identify(info)
+	if s.identity() != prev {
+		s.generation++
+	}
+	s.pending = 0
+	s.stuckPolls = 0
+	s.lastSize = 0
+	s.setAtEOF(false)
+	s.fatal = nil
+
+	// The backlog has already been replayed.
+	s.o.mmap = false
+
+	s.t.updateStats(func(st *Stats) { st.Reopens++ })
+	s.emit(EventReopened)
+	return true
+}
@@ -0,0 +1,123 @@
+package tailf
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// badRecordFramer frames lines, failing on a line reading "bad" while
+// fails is positive.
+type badRecordFramer struct {
+	fails int
+}
+
+func (f *badRecordFramer) Frame(r *bufio.Reader) (int, []byte, error) {
+	n, payload, err := lineFramer{}.Frame(r)
+	if err == nil && string(payload) == "bad" && f.fails > 0 {
+		f.fails--
+		return n, nil, errors.New("bad record")
+	}
+	return n, payload, err
+}
+
+func TestSuperviseRestarts(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("one\nbad\ntwo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	events := make(chan Event, 10)
+	tailer, err := Follow(ctx, path,
+		WithFromStart(true),
+		WithFramer(&badRecordFramer{fails: 1}),
+		WithSupervise(10*time.Millisecond, 3),
+		WithEventHandler(func(e Event) { events <- e }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The failed record is skipped; tailing resumes after it.
+	for _, want := range []string{"one", "two"} {
+		select {
+		case line := <-tailer.Lines():
+			if line.Text != want {
+				t.Errorf("got %q, want %q", line.Text, want)
+			}
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+	}
+	if e := <-events; e.Type != EventReopened {
+		t.Errorf("event = %v, want %v", e.Type, EventReopened)
+	}
+	if st := tailer.Stats(); st.Reopens != 1 {
+		t.Errorf("Reopens = %d, want 1", st.Reopens)
+	}
+
+	cancel()
+	<-tailer.Done()
+	if err := tailer.Err(); err != nil {
+		t.Errorf("Err = %v, want nil", err)
+	}
+}
+
+func TestSuperviseGivesUp(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("bad\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path,
+		WithFromStart(true),
+		WithFramer(&badRecordFramer{fails: 10}),
+		WithSupervise(10*time.Millisecond, 2),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each restart resumes after the bad line, so write it again.
+	writerDone := make(chan struct{})
+	defer func() { cancel(); <-writerDone }()
+	go func() {
+		defer close(writerDone)
+		for ctx.Err() == nil {
+			time.Sleep(20 * time.Millisecond)
+			f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				return
+			}
+			f.WriteString("bad\n")
+			f.Close()
+		}
+	}()
+
+	select {
+	case <-tailer.Done():
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the tailer to give up")
+	}
+	var te *TailError
+	if !errors.As(tailer.Err(), &te) || te.Op != "read" {
+		t.Errorf("Err = %v, want a read TailError", tailer.Err())
+	}
+	if st := tailer.Stats(); st.Reopens != 2 {
+		t.Errorf("Reopens = %d, want 2", st.Reopens)
+	}
+}
@@ -0,0 +1,25 @@
+package tailf
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// resolvePath returns the file path names under [WithFollowSymlink]
+// false: path with its symbolic links resolved, or path itself if that
+// fails. Otherwise it returns path unchanged.
+func resolvePath(path string, o options) string {
+	if o.followSymlink {
+		return path
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return path
+}
+
+// isSymlink reports whether path is itself a symbolic link.
+func isSymlink(path string) bool {
+	info, err := os.Lstat(path)
+	return err == nil && info.Mode()&os.ModeSymlink != 0
+}
@@ -0,0 +1,155 @@
+package tailf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// appendLine appends text and a newline to the file at path.
+func appendLine(t *testing.T, path, text string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := fmt.Fprintln(f, text); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// nextLine returns the next line from tailer, failing the test if none
+// arrives before ctx is done.
+func nextLine(ctx context.Context, t *testing.T, tailer *Tailer) string {
+	t.Helper()
+	select {
+	case line := <-tailer.Lines():
+		return line.Text
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for a line")
+		return ""
+	}
+}
+
+func TestFollowFileReplacedBySymlink(t *testing.T) {
+	if !RotationDetectionSupported() {
+		t.Skip("no file identities on this platform")
+	}
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "app.log")
+	target := filepath.Join(tmp, "releases", "app.log")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendLine(t, path, "regular")
+	if got := nextLine(ctx, t, tailer); got != "regular" {
+		t.Fatalf("got %q, want %q", got, "regular")
+	}
+
+	// A deployment replaces the file with a link to a new one.
+	if err := os.Mkdir(filepath.Dir(target), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, []byte("linked\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, path); err != nil {
+		t.Skipf("symlink: %v", err)
+	}
+	if got := nextLine(ctx, t, tailer); got != "linked" {
+		t.Errorf("got %q, want %q", got, "linked")
+	}
+	if n := tailer.Stats().Rotations; n != 1 {
+		t.Errorf("got %d rotations, want 1", n)
+	}
+}
+
+func TestFollowFileMovedBehindSymlink(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "app.log")
+	moved := filepath.Join(tmp, "app.log.real")
+	if err := os.WriteFile(path, []byte("before\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFromStart(true), WithPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := nextLine(ctx, t, tailer); got != "before" {
+		t.Fatalf("got %q, want %q", got, "before")
+	}
+	<-tailer.CaughtUp()
+
+	// The same file now sits behind a link: nothing is read again.
+	if err := os.Rename(path, moved); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(moved, path); err != nil {
+		t.Skipf("symlink: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	appendLine(t, path, "after")
+	if got := nextLine(ctx, t, tailer); got != "after" {
+		t.Errorf("got %q, want %q", got, "after")
+	}
+	if st := tailer.Stats(); st.Rotations != 0 || st.Reopens != 1 {
+		t.Errorf("got %d rotations, %d reopens, want 0, 1", st.Rotations, st.Reopens)
+	}
+}
+
+func TestFollowSymlinkResolvedOnce(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "current")
+	first := filepath.Join(tmp, "first.log")
+	second := filepath.Join(tmp, "second.log")
+	for _, p := range []string{first, second} {
+		if err := os.WriteFile(p, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(first, path); err != nil {
+		t.Skipf("symlink: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFollowSymlink(false), WithPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Repointing the link does not move the tailer off the first file.
+	swap := path + ".tmp"
+	if err := os.Symlink(second, swap); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(swap, path); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	appendLine(t, second, "second")
+	appendLine(t, first, "first")
+	if got := nextLine(ctx, t, tailer); got != "first" {
+		t.Errorf("got %q, want %q", got, "first")
+	}
+}
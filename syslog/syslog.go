@@ -0,0 +1,293 @@
+// Package syslog tails files of syslog messages and parses them into
+// structured form. Both the RFC 5424 format and the older BSD format of
+// RFC 3164 are recognized, line by line, so files that mix them are
+// handled too.
+package syslog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Splat/go-tailf"
+	"github.com/Splat/go-tailf/internal/follow"
+)
+
+// Format identifies the syslog format a message was written in.
+type Format int
+
+const (
+	// RFC3164 is the BSD syslog format, as in
+	// "<34>Oct 11 22:14:15 mymachine su: 'su root' failed".
+	RFC3164 Format = iota + 1
+
+	// RFC5424 is the IETF syslog format, as in
+	// "<165>1 2003-10-11T22:14:15.003Z mymachine evntslog - ID47 - msg".
+	RFC5424
+)
+
+// Message is a parsed syslog message. Fields absent from the message,
+// or given as the RFC 5424 nil value "-", are left empty.
+type Message struct {
+	Format Format
+
+	// Priority is the facility times 8 plus the severity, or -1 if the
+	// line has no priority, as is common in files written by syslog
+	// daemons.
+	Priority int
+
+	Timestamp time.Time
+	Hostname  string
+	AppName   string
+	ProcID    string
+	MsgID     string
+
+	// StructuredData maps the ID of each RFC 5424 structured data
+	// element to its parameters.
+	StructuredData map[string]map[string]string
+
+	Message string
+
+	// Line is the line the message was parsed from.
+	Line tailf.Line
+}
+
+// Facility returns the facility code of the priority, or -1 if there is
+// none.
+func (m Message) Facility() int {
+	if m.Priority < 0 {
+		return -1
+	}
+	return m.Priority / 8
+}
+
+// Severity returns the severity code of the priority, from 0 (emergency)
+// to 7 (debug), or -1 if there is none.
+func (m Message) Severity() int {
+	if m.Priority < 0 {
+		return -1
+	}
+	return m.Priority % 8
+}
+
+// ParseError reports a line that is not a syslog message.
+type ParseError struct {
+	// Line is the text of the malformed line.
+	Line string
+
+	// Reason says what is wrong with it.
+	Reason string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("syslog: %s: %q", e.Reason, e.Line)
+}
+
+// FollowSyslog tails the file at path, as [tailf.Follow] does with opts,
+// and parses each line as a syslog message. Messages are delivered on
+// the first channel. Malformed lines are reported on the second as
+// [*ParseError] values, followed by the tailer's fatal error, if any.
+// Both channels are closed once the tailer stops, which happens when ctx
+// is cancelled or the returned stop function is called.
+//
+// The error channel must be drained along with the message channel, or
+// tailing stalls, until stop is called; the channels need not be drained
+// after that.
+func FollowSyslog(ctx context.Context, path string, opts ...tailf.Option) (<-chan Message, <-chan error, func()) {
+	return follow.Parsed(ctx, path, opts, func(line tailf.Line) (Message, error) {
+		m, err := Parse(line.Text)
+		m.Line = line
+		return m, err
+	})
+}
+
+// Parse parses a single syslog line, detecting its format. Lines with a
+// version number after the priority are parsed as RFC 5424, all others
+// as RFC 3164. The returned error is a [*ParseError].
+func Parse(text string) (Message, error) {
+	m := Message{Priority: -1}
+	rest := text
+	if strings.HasPrefix(rest, "<") {
+		end := strings.IndexByte(rest, '>')
+		if end < 2 || end > 4 {
+			return m, &ParseError{Line: text, Reason: "bad priority"}
+		}
+		pri, err := strconv.Atoi(rest[1:end])
+		if err != nil || pri < 0 || pri > 191 {
+			return m, &ParseError{Line: text, Reason: "bad priority"}
+		}
+		m.Priority = pri
+		rest = rest[end+1:]
+
+		if len(rest) > 1 && rest[0] >= '1' && rest[0] <= '9' && rest[1] == ' ' {
+			err := parse5424(&m, rest[2:])
+			if err != nil {
+				return m, &ParseError{Line: text, Reason: err.Error()}
+			}
+			return m, nil
+		}
+	}
+
+	if err := parse3164(&m, rest); err != nil {
+		return m, &ParseError{Line: text, Reason: err.Error()}
+	}
+	return m, nil
+}
+
+// parse5424 parses an RFC 5424 message after "<PRI>VERSION ".
+func parse5424(m *Message, s string) error {
+	m.Format = RFC5424
+
+	var fields [5]string
+	for i := range fields {
+		f, rest, ok := strings.Cut(s, " ")
+		if !ok {
+			return errors.New("missing header fields")
+		}
+		fields[i], s = f, rest
+	}
+	if fields[0] != "-" {
+		ts, err := time.Parse(time.RFC3339Nano, fields[0])
+		if err != nil {
+			return errors.New("bad timestamp")
+		}
+		m.Timestamp = ts
+	}
+	m.Hostname = nilValue(fields[1])
+	m.AppName = nilValue(fields[2])
+	m.ProcID = nilValue(fields[3])
+	m.MsgID = nilValue(fields[4])
+
+	sd, rest, err := parseStructuredData(s)
+	if err != nil {
+		return err
+	}
+	m.StructuredData = sd
+	if rest != "" {
+		if rest[0] != ' ' {
+			return errors.New("bad structured data")
+		}
+		m.Message = strings.TrimPrefix(rest[1:], "\ufeff")
+	}
+	return nil
+}
+
+func nilValue(s string) string {
+	if s == "-" {
+		return ""
+	}
+	return s
+}
+
+// parseStructuredData parses the structured data at the start of s and
+// returns the rest of s.
+func parseStructuredData(s string) (map[string]map[string]string, string, error) {
+	if strings.HasPrefix(s, "-") {
+		return nil, s[1:], nil
+	}
+	if !strings.HasPrefix(s, "[") {
+		return nil, s, errors.New("bad structured data")
+	}
+
+	sd := make(map[string]map[string]string)
+	for strings.HasPrefix(s, "[") {
+		s = s[1:]
+		end := strings.IndexAny(s, " ]")
+		if end <= 0 {
+			return nil, s, errors.New("bad structured data element")
+		}
+		id := s[:end]
+		params := make(map[string]string)
+		s = s[end:]
+		for strings.HasPrefix(s, " ") {
+			s = s[1:]
+			name, rest, ok := strings.Cut(s, `="`)
+			if !ok || name == "" {
+				return nil, s, errors.New("bad structured data parameter")
+			}
+			value, rest, err := parseParamValue(rest)
+			if err != nil {
+				return nil, s, err
+			}
+			params[name] = value
+			s = rest
+		}
+		if !strings.HasPrefix(s, "]") {
+			return nil, s, errors.New("unterminated structured data element")
+		}
+		s = s[1:]
+		sd[id] = params
+	}
+	return sd, s, nil
+}
+
+// parseParamValue parses a parameter value up to its closing quote,
+// undoing the escapes of '"', '\' and ']'.
+func parseParamValue(s string) (string, string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"':
+			return b.String(), s[i+1:], nil
+		case c == '\\' && i+1 < len(s) && strings.IndexByte(`"\]`, s[i+1]) >= 0:
+			i++
+			b.WriteByte(s[i])
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", s, errors.New("unterminated structured data value")
+}
+
+// stamp3164 is the layout of RFC 3164 timestamps, which have no year.
+const stamp3164 = "Jan _2 15:04:05"
+
+// parse3164 parses an RFC 3164 message after the optional "<PRI>".
+func parse3164(m *Message, s string) error {
+	m.Format = RFC3164
+
+	if len(s) < len(stamp3164)+1 || s[len(stamp3164)] != ' ' {
+		return errors.New("missing timestamp")
+	}
+	ts, err := time.ParseInLocation(stamp3164, s[:len(stamp3164)], time.Local)
+	if err != nil {
+		return errors.New("bad timestamp")
+	}
+	m.Timestamp = withYear(ts, time.Now())
+	s = s[len(stamp3164)+1:]
+
+	host, rest, ok := strings.Cut(s, " ")
+	if !ok || host == "" {
+		return errors.New("missing hostname")
+	}
+	m.Hostname = host
+
+	// The tag is the application name with an optional process ID in
+	// brackets, ended by a colon. Messages without one are kept whole.
+	if tag, msg, ok := strings.Cut(rest, ": "); ok && !strings.ContainsRune(tag, ' ') {
+		m.AppName = tag
+		if open := strings.IndexByte(tag, '['); open > 0 && strings.HasSuffix(tag, "]") {
+			m.AppName, m.ProcID = tag[:open], tag[open+1:len(tag)-1]
+		}
+		rest = msg
+	}
+	m.Message = rest
+	return nil
+}
+
+// withYear gives ts, which has no year, the year that puts it closest to
+// now, so that messages from late December read in January are dated to
+// the previous year.
+func withYear(ts, now time.Time) time.Time {
+	ts = ts.AddDate(now.Year(), 0, 0)
+	switch {
+	case ts.Sub(now) > 183*24*time.Hour:
+		return ts.AddDate(-1, 0, 0)
+	case now.Sub(ts) > 183*24*time.Hour:
+		return ts.AddDate(1, 0, 0)
+	}
+	return ts
+}
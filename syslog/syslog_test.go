@@ -0,0 +1,141 @@
+package syslog
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Splat/go-tailf"
+)
+
+func TestParse5424(t *testing.T) {
+	line := `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventSource="App\"lication"][examplePriority@32473 class="high"] ` + "\ufeffAn application event"
+	m, err := Parse(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Message{
+		Format:    RFC5424,
+		Priority:  165,
+		Timestamp: time.Date(2003, 10, 11, 22, 14, 15, 3e6, time.UTC),
+		Hostname:  "mymachine.example.com",
+		AppName:   "evntslog",
+		MsgID:     "ID47",
+		StructuredData: map[string]map[string]string{
+			"exampleSDID@32473":     {"iut": "3", "eventSource": `App"lication`},
+			"examplePriority@32473": {"class": "high"},
+		},
+		Message: "An application event",
+	}
+	if !m.Timestamp.Equal(want.Timestamp) {
+		t.Errorf("Timestamp = %v, want %v", m.Timestamp, want.Timestamp)
+	}
+	m.Timestamp = want.Timestamp
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("got  %+v\nwant %+v", m, want)
+	}
+	if m.Facility() != 20 || m.Severity() != 5 {
+		t.Errorf("facility %d, severity %d; want 20, 5", m.Facility(), m.Severity())
+	}
+}
+
+func TestParse5424NilValues(t *testing.T) {
+	m, err := Parse("<14>1 - - - - - -")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Timestamp.IsZero() || m.Hostname != "" || m.StructuredData != nil || m.Message != "" {
+		t.Errorf("got %+v, want empty fields", m)
+	}
+}
+
+func TestParse3164(t *testing.T) {
+	tests := []struct {
+		line                       string
+		pri                        int
+		host, app, procID, message string
+	}{
+		{"<34>Oct 11 22:14:15 mymachine su: 'su root' failed for lonvick", 34, "mymachine", "su", "", "'su root' failed for lonvick"},
+		{"Feb  5 08:00:01 web1 sshd[4242]: Accepted publickey", -1, "web1", "sshd", "4242", "Accepted publickey"},
+		{"<13>Feb  5 08:00:01 web1 no tag here", 13, "web1", "", "", "no tag here"},
+	}
+	for _, tt := range tests {
+		m, err := Parse(tt.line)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.line, err)
+			continue
+		}
+		if m.Format != RFC3164 || m.Priority != tt.pri || m.Hostname != tt.host ||
+			m.AppName != tt.app || m.ProcID != tt.procID || m.Message != tt.message {
+			t.Errorf("Parse(%q) = %+v", tt.line, m)
+		}
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	for _, line := range []string{
+		"just some text",
+		"<999>Oct 11 22:14:15 host app: msg",
+		"<14>1 2003-10-11T22:14:15Z host",
+		"<14>1 - - - - - [unterminated",
+	} {
+		_, err := Parse(line)
+		var pe *ParseError
+		if !errors.As(err, &pe) || pe.Line != line {
+			t.Errorf("Parse(%q) error = %v, want a ParseError", line, err)
+		}
+	}
+}
+
+func TestWithYear(t *testing.T) {
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	ts := time.Date(0, 12, 31, 23, 59, 0, 0, time.UTC)
+	if got := withYear(ts, now); got.Year() != 2023 {
+		t.Errorf("December stamp read in January got year %d, want 2023", got.Year())
+	}
+}
+
+func TestFollowSyslog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages")
+	content := "<13>1 2024-05-01T10:00:00Z host app 1 - - started\n" +
+		"garbage\n" +
+		"<13>May  1 10:00:01 host app[1]: running\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	msgs, errs, stop := FollowSyslog(ctx, path, tailf.WithFromStart(true))
+	defer stop()
+
+	var got []string
+	for len(got) < 3 {
+		select {
+		case m := <-msgs:
+			got = append(got, m.Message)
+		case err := <-errs:
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("unexpected error %v", err)
+			}
+			got = append(got, "error: "+pe.Line)
+		case <-ctx.Done():
+			t.Fatalf("timed out after %q", got)
+		}
+	}
+	if want := []string{"started", "error: garbage", "running"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	stop()
+	for range msgs {
+	}
+	for range errs {
+	}
+}
@@ -9,30 +9,128 @@ package tailf
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
-	"strings"
 	"sync"
 	"time"
 )
 
+// ErrIsDirectory is returned by [Follow] when the path names a directory
+// rather than a file.
+var ErrIsDirectory = errors.New("path is a directory")
+
+// ErrFileTooLarge is reported by [Tailer.Err] when the tailed file grew
+// beyond the [WithMaxFileSize] limit.
+var ErrFileTooLarge = errors.New("file exceeds size limit")
+
 // Line represents a single line read from the tailed file.
 type Line struct {
 	// Text is the line content with trailing newline characters stripped.
 	Text string
 
-	// Time is when the line was read by the tailer.
+	// Terminator holds the characters stripped from the end of Text,
+	// usually "\n" or "\r\n", so that Text+Terminator reproduces the
+	// bytes read. It is empty for a line without a terminator and for
+	// records produced by a custom [Framer].
+	Terminator string
+
+	// Raw holds the bytes of a fixed-size record read with
+	// [WithFixedRecordSize], whose Text is the same bytes as a string.
+	// It is nil for other lines.
+	Raw []byte
+
+	// Time is when the line was read by the tailer. It carries a
+	// monotonic clock reading, so the Times of two lines of the same
+	// tailer compare and subtract reliably even if the wall clock is
+	// adjusted, and a line read later never has an earlier Time. Lines
+	// released by [WithReorder] are delivered out of reading order, and
+	// so out of Time order. Encoding a Line, as with encoding/json,
+	// keeps only the wall clock reading.
 	Time time.Time
+
+	// Seq numbers the lines in the order the tailer read them, from 1,
+	// across rotations and switches to other files. Lines skipped by
+	// filters or as duplicates use up their numbers too, leaving gaps.
+	// It is 0 for in-band markers.
+	Seq int64
+
+	// Level is the severity extracted by the [WithLevelParser] parser,
+	// or empty if there is none or it did not recognize the line.
+	Level string
+
+	// Source is the path the line was read from, as given to
+	// [FollowMulti]. It is empty for lines of other tailers.
+	Source string
+
+	// RotationIndex is the rotation generation of the file the line was
+	// read from, such as 1 for "app.log.1", or 0 for the live file. See
+	// [WithRotationIndexFunc].
+	RotationIndex int
+
+	// Partial is set on the last line of a file read with
+	// [WithStopAtEOF] if the file does not end with a line terminator,
+	// and on the torn first line delivered when [WithAlignToLine] is off.
+	Partial bool
+
+	// Marker is set on synthetic lines injected into the stream by
+	// [WithInBandMarkers]. It is [NoMarker] for lines read from the file.
+	Marker Marker
+
+	// Meta holds data attached by the [WithLineDecorator] function. It is
+	// nil unless the decorator sets it. A line queued by
+	// [WithSpillToDisk] is stored with encoding/json, so its Meta comes
+	// back as JSON decodes it: numbers as float64, structs as
+	// map[string]any and so on.
+	Meta map[string]any
 }
 
+// Marker identifies a synthetic, in-band signal delivered on the
+// [Tailer.Lines] channel instead of file content.
+type Marker int
+
+const (
+	// NoMarker is the zero value carried by ordinary lines.
+	NoMarker Marker = iota
+
+	// CaughtUp is delivered once, when the tailer reaches the end of the
+	// existing content for the first time and begins following live.
+	CaughtUp
+)
+
+// lineBuffer is the capacity of the Lines channel.
+const lineBuffer = 64
+
 // Tailer follows a file and emits lines as they are appended.
 // Create one with [Follow] and receive lines from [Tailer.Lines].
 type Tailer struct {
-	lines chan Line
-	err   error
-	mu    sync.Mutex
-	done  chan struct{}
+	lines    chan Line
+	err      error
+	mu       sync.Mutex
+	done     chan struct{}
+	caughtUp chan struct{}
+	stopping chan struct{} // closed when the helper goroutines are to return
+	helpers  sync.WaitGroup
+	cmds     chan command
+	stats    Stats
+	rate     throughput
+	fill     fillGauge
+	sent     sentLog
+	held     int64
+	final    Stats
+
+	initialSize int64
+	atEOF       bool
+
+	subs       map[chan Line]struct{}
+	matchers   map[*matcher]struct{}
+	subsClosed bool
+
+	closed bool
+
+	agg   Aggregator
+	aggMu sync.Mutex
 }
 
 // Lines returns a read-only channel that receives lines as they appear
@@ -42,9 +140,17 @@ func (t *Tailer) Lines() <-chan Line {
 	return t.lines
 }
 
+// Pending returns the number of lines that have been read from the file
+// but not yet received from [Tailer.Lines]. Consumers can watch it to
+// detect that they are falling behind. It is safe to call concurrently.
+func (t *Tailer) Pending() int {
+	return len(t.lines)
+}
+
 // Err returns the error that caused the tailer to stop, or nil if it
-// was stopped by context cancellation. Only meaningful after the
-// [Tailer.Lines] channel has been closed.
+// was stopped by context cancellation. A [WithStopAtEOF] session may
+// also end with the non-fatal [ErrTrailingPartial]. Only meaningful
+// after the [Tailer.Lines] channel has been closed.
 func (t *Tailer) Err() error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -57,6 +163,22 @@ func (t *Tailer) Done() <-chan struct{} {
 	return t.done
 }
 
+// Closed reports whether the tailer has released its file handle and
+// any spill file. It is always true once [Tailer.Done] is closed, which
+// lets tests check that a stopped tailer leaks no file descriptor.
+func (t *Tailer) Closed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.closed
+}
+
+// CaughtUp returns a channel that is closed when the tailer reaches the
+// end of the file for the first time, i.e. when the initial backlog has
+// been read and live following begins.
+func (t *Tailer) CaughtUp() <-chan struct{} {
+	return t.caughtUp
+}
+
 func (t *Tailer) setErr(err error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -75,201 +197,1248 @@ func Follow(ctx context.Context, path string, opts ...Option) (*Tailer, error) {
 		opt(&o)
 	}
 
-	file, reader, fileID, err := openFile(path, o)
+	s, err := newTailState(path, o)
 	if err != nil {
 		return nil, fmt.Errorf("tailf: %w", err)
 	}
+	return s.start(ctx), nil
+}
+
+// newTailState opens path according to o and prepares a Tailer for it.
+// The tailer does not run until start is called.
+func newTailState(path string, o options) (*tailState, error) {
+	path = resolvePath(path, o)
+	primary := path
+	if o.framer == nil {
+		o.framer = lineFramer{strict: o.strictCRLF, delims: o.delimiters}
+	}
+	if o.gzip {
+		// Compressed input can only start at a member boundary.
+		o.startPercent, o.initialBytes = -1, 0
+	}
+
+	file, reader, info, torn, err := openFile(path, o)
+	for _, fallback := range o.fallbackPaths {
+		if err == nil {
+			break
+		}
+		if f, r, fi, tn, ferr := openFile(fallback, o); ferr == nil {
+			file, reader, info, torn, err = f, r, fi, tn, nil
+			path = fallback
+		}
+	}
+	var succ *successor
+	if err != nil && !errors.Is(err, ErrIsDirectory) {
+		succ = openSuccessor(primary, o)
+	}
+	if err != nil {
+		// Under WithFollowByName, or after replaying a compressed
+		// successor, the tailing goroutine waits for the file instead;
+		// see waitForFile.
+		if succ == nil && !o.followByName || errors.Is(err, ErrIsDirectory) {
+			return nil, err
+		}
+		path = primary
+	}
 
 	t := &Tailer{
-		lines: make(chan Line, 64),
-		done:  make(chan struct{}),
+		lines:    make(chan Line, lineBuffer),
+		sent:     newSentLog(lineBuffer),
+		done:     make(chan struct{}),
+		caughtUp: make(chan struct{}),
+		stopping: make(chan struct{}),
+		cmds:     make(chan command),
+		rate:     throughput{window: o.throughputWindow},
+		agg:      o.aggregator,
+
+		initialSize: -1,
+	}
+	if file != nil {
+		t.setInitialSize(file)
 	}
 
+	s := &tailState{
+		t:       t,
+		o:       o,
+		primary: primary,
+		path:    path,
+		file:    file,
+		before:  newLineRing(o.contextBefore),
+	}
+	s.scan = lineScanner{r: reader, o: &s.o, torn: torn}
+	s.indexPath()
+	if file != nil {
+		s.identify(info)
+		s.expectHeader()
+	}
+	s.recent = newHashRing(o.dedupRecent)
+	if o.reorderSeq != nil {
+		s.reordering = &reorderBuffer{}
+	}
+	if o.spill {
+		s.spill = &spillQueue{dir: o.spillDir, maxBytes: o.spillMax, clock: time.Now()}
+	}
+	if succ != nil {
+		s.useSuccessor(succ)
+	}
+	if o.gzip && file != nil {
+		s.scan.r = s.newReader(file)
+	}
+	if file != nil && !o.resumeToken.IsZero() {
+		if err := s.resume(info.Size()); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	if file != nil {
+		s.markStart()
+	}
+	return s, nil
+}
+
+// start runs the tailing goroutine and returns its Tailer.
+func (s *tailState) start(ctx context.Context) *Tailer {
+	t := s.t
+	if len(s.o.notifyChans) > 0 {
+		s.o.notify = mergeNotify(ctx, t.done, s.o.notify, s.o.notifyChans)
+	}
+	if s.o.replaySignal != nil {
+		t.watchReplaySignal(s.o.replaySignal)
+	}
+	if s.o.progress != nil {
+		t.reportProgress(s.o.progressEvery, s.o.progress)
+	}
+	if s.o.pressure != nil {
+		t.signalPressure(s.o.pressure, s.o.memoryBudget)
+	}
+	var unregister func()
+	if s.o.scheduler != nil {
+		s.tick, unregister = s.o.scheduler.register()
+	}
 	go func() {
 		defer close(t.done)
+		defer t.stopHelpers()
+		if unregister != nil {
+			defer unregister()
+		}
 		defer close(t.lines)
-		defer file.Close()
-		if err := tailLoop(ctx, t, file, reader, fileID, path, o); err != nil {
+		defer t.closeSubscribers()
+		defer s.close()
+		t.updateStats(func(st *Stats) { st.Started = time.Now() })
+		s.announce(LifecycleEvent{Type: LifecycleStarted})
+		if s.file != nil {
+			s.announce(LifecycleEvent{Type: LifecycleOpened})
+		}
+		if err := s.supervise(ctx); err != nil {
 			t.setErr(err)
 		}
+		s.drainReorder()
+		final := t.recordStop(ctx)
+		s.announce(LifecycleEvent{Type: LifecycleStopped, Err: t.Err(), Stats: &final})
 	}()
+	return t
+}
 
-	return t, nil
+// helper runs fn on a goroutine of its own, which the tailing goroutine
+// waits for before closing Done, so that nothing fn does can happen after
+// the tailer has stopped. fn must return once t.stopping is closed.
+func (t *Tailer) helper(fn func()) {
+	t.helpers.Add(1)
+	go func() {
+		defer t.helpers.Done()
+		fn()
+	}()
+}
+
+// stopHelpers tells the goroutines started by helper to return and waits
+// for them.
+func (t *Tailer) stopHelpers() {
+	close(t.stopping)
+	t.helpers.Wait()
 }
 
 // FollowFunc tails the given file and calls fn for each line.
 // It blocks until ctx is cancelled or a fatal error occurs.
 //
+// If fn panics, the tailer is stopped and its file handle released
+// before FollowFunc returns the panic as an error.
+//
 // This is a convenience wrapper for cases where a channel is not needed.
-func FollowFunc(ctx context.Context, path string, fn func(Line), opts ...Option) error {
+func FollowFunc(ctx context.Context, path string, fn func(Line), opts ...Option) (err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	t, err := Follow(ctx, path, opts...)
 	if err != nil {
 		return err
 	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			cancel()
+			<-t.Done()
+			err = fmt.Errorf("tailf: callback panicked: %v", r)
+		}
+	}()
+
 	for line := range t.Lines() {
 		fn(line)
 	}
 	return t.Err()
 }
 
-func tailLoop(ctx context.Context, t *Tailer, file *os.File, reader *bufio.Reader, fileID fileIdentity, path string, o options) error {
-	var partialLine string
+// Each tails the given file and calls fn for each line until fn returns
+// false or a fatal error occurs. It blocks for the whole time and returns
+// nil when fn asked to stop.
+//
+// Each is the simplest way to tail a file from a script or small tool:
+// it needs no context and cleans up after itself. Programs that need to
+// control the tailer's lifetime should use [Follow] or [FollowFunc].
+func Each(path string, fn func(Line) bool, opts ...Option) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	t, err := Follow(ctx, path, opts...)
+	if err != nil {
+		return err
+	}
+	for line := range t.Lines() {
+		if !fn(line) {
+			cancel()
+			<-t.Done()
+			return nil
+		}
+	}
+	return t.Err()
+}
+
+// tailState is the mutable state of a tailing session. It is owned by
+// the tailing goroutine and must not be touched from anywhere else.
+type tailState struct {
+	t       *Tailer
+	o       options
+	primary string // path as requested by the caller
+	path    string // path currently being followed
+
+	file   *os.File
+	scan   lineScanner
+	fileID fileIdentity
+
+	// isLink is set if the path was a symbolic link when the current
+	// file was opened.
+	isLink bool
+
+	// customID is the [WithIdentityFunc] identity of the current file,
+	// or "" if there is none or it could not be computed.
+	customID string
+
+	// gz decompresses the file under [WithGzipInput]; the scanner then
+	// reads from it instead of from file.
+	gz *gzipSource
+
+	// pending is the length of the incomplete record at the current
+	// position, as seen by the last read that hit EOF.
+	pending  int
+	caughtUp bool
+
+	// atEOF is set while the last read reached the end of the file.
+	atEOF bool
+
+	// paused is set between [Tailer.Pause] and [Tailer.Resume].
+	paused bool
+
+	// lastRotation is when the tailer last switched to a rotated file;
+	// justRotated is set until the first poll after that switch.
+	lastRotation time.Time
+	justRotated  bool
+
+	// drainSince is when the tailer started draining a replaced file
+	// before switching to the new one; see [WithRotationDrainTimeout].
+	drainSince time.Time
+
+	// stuckPolls counts consecutive EOF polls during which the path
+	// reported more data than our handle could read.
+	stuckPolls int
+
+	// lastSize is the size of the current file at the previous poll,
+	// used by [WithRestartOnShrink].
+	lastSize int64
+
+	// before holds the most recent filtered-out lines, kept as leading
+	// context for the next match; afterLeft counts the trailing context
+	// lines still to deliver after the last match.
+	before    lineRing
+	afterLeft int
+
+	// epoch is incremented whenever buffered output is discarded, so
+	// that lines produced before the discard are not delivered after it.
+	epoch int
+
+	// pull is set for a [PullTailer], whose lines are queued for the
+	// caller's goroutine instead of being sent on the Lines channel.
+	pull  bool
+	queue []Line
+
+	// stopped is set once a [WithStopAtEOF] session has ended.
+	stopped bool
+
+	// seekingMarker is set while lines are skipped in search of the
+	// [WithStartAfterMarker] marker; startOffset and startTorn record
+	// where reading started, to read again if it is not found.
+	seekingMarker bool
+	startOffset   int64
+	startTorn     bool
+
+	// generation counts the rotations, truncations and switches to
+	// another file, and seqBase the lines delivered before a
+	// [WithResumeToken] token, as recorded in a [Token].
+	generation int64
+	seqBase    int64
+
+	// readSeq is the [Line].Seq of the last line read.
+	readSeq int64
+
+	// inFlight is the length of the record of the line being delivered,
+	// which [Tailer.Position] places after the position.
+	inFlight int
+
+	// sendingAt is where the line being sent was read, recorded with
+	// it when it is held back or queued on disk.
+	sendingAt linePos
+
+	// mapping is set during the [WithMmap] replay, while mapOff is the
+	// offset of the next byte to be framed from the mapping.
+	mapping bool
+	mapOff  int64
+
+	// eofSince is when the tailer last reached the end of the file; see
+	// [WithCaughtUpStable].
+	eofSince time.Time
+
+	// partialSince is when the incomplete line at the end of the file
+	// last changed length; see [WithLineTimeout].
+	partialSince time.Time
+
+	// lastNotify is when a notification last woke the tailer; see
+	// [WithNotifyCoalesce].
+	lastNotify time.Time
+
+	// noDeadline is set once the current file has refused a read
+	// deadline; see [WithReadDeadline].
+	noDeadline bool
+
+	// fatal is an error raised while delivering a line, which stops
+	// the tailer once delivery has unwound.
+	fatal error
+
+	// tick, if set, receives the ticks of the [WithScheduler] scheduler,
+	// which replace the poll timer.
+	tick <-chan struct{}
+
+	// recent, if set, holds the hashes of the last lines seen for
+	// [WithDedupRecent].
+	recent *hashRing
+
+	// reordering, if set, holds lines back for [WithReorder].
+	reordering *reorderBuffer
+
+	// spill, if set, queues lines on disk while the Lines channel is
+	// full; see [WithSpillToDisk].
+	spill *spillQueue
+
+	// next, if set, is consulted at EOF for the path that should be
+	// followed instead of the current one, as used by [FollowLatest].
+	next func(current string) string
+
+	// succ, if set, is the compressed successor of the file to resume,
+	// read before the file at the path; see [WithCompressedResume].
+	succ *successor
+}
+
+// close releases the file handle and spill file. The tailing goroutine
+// calls it before closing the Done channel.
+func (s *tailState) close() {
+	if s.file != nil {
+		s.file.Close()
+	}
+	if s.spill != nil {
+		s.spill.close()
+	}
+	if s.succ != nil {
+		s.succ.file.Close()
+	}
+	s.t.mu.Lock()
+	s.t.closed = true
+	s.t.mu.Unlock()
+}
+
+func tailLoop(ctx context.Context, s *tailState) error {
+	if s.o.mmap {
+		ok, err := s.replayMapped(ctx)
+		if err != nil || !ok {
+			return err
+		}
+	}
 
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
+		case c := <-s.t.cmds:
+			s.run(c)
+			continue
 		default:
 		}
 
-		line, err := reader.ReadString('\n')
-		if err != nil {
-			if err != io.EOF {
-				return fmt.Errorf("read error: %w", err)
+		if s.paused {
+			if !s.waitWhilePaused(ctx) {
+				return nil
 			}
+			continue
+		}
 
-			// EOF: buffer any partial data and check for truncation/rotation.
-			partialLine += line
+		ok, err := s.step(ctx)
+		if err != nil || !ok {
+			return err
+		}
+	}
+}
+
+// step reads and delivers one record or, at EOF, handles truncation,
+// rotation and switching before waiting for more data. It returns false
+// if ctx was cancelled.
+func (s *tailState) step(ctx context.Context) (bool, error) {
+	if s.atEOF && s.o.restartOnShrink && !s.o.appendOnly {
+		// Catch a rewrite that happened while we waited before reading
+		// any of it from the old position.
+		if err := s.restartIfShrunk(); err != nil {
+			return false, err
+		}
+	}
 
-			var reopened bool
-			file, reader, fileID, reopened, err = checkFileState(file, reader, fileID, path)
-			if err != nil {
-				return err
+	if s.o.readDeadline > 0 {
+		s.armDeadline()
+	}
+	line, ok, err := s.scan.Scan()
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		// The read hung. Give the caller a chance to see ctx, then
+		// retry from the start of the record.
+		if err := s.rewind(s.scan.pending); err != nil {
+			return false, err
+		}
+		return ctx.Err() == nil, nil
+	}
+	if err != nil {
+		var pe *os.PathError
+		if !errors.As(err, &pe) {
+			// The framer rejected the record it consumed rather than
+			// reading it failing midway, so resume after it.
+			s.scan.pending = 0
+		}
+		return false, s.fail("read", err)
+	}
+	if s.scan.n > 0 {
+		s.stuckPolls = 0
+		s.t.recordRead(s.scan.n)
+	}
+
+	if !ok {
+		if s.seekingMarker {
+			if again, err := s.markerNotFound(); err != nil || again {
+				return err == nil, err
 			}
-			if reopened {
-				partialLine = ""
+		}
+		idle := s.atEOF && time.Since(s.eofSince) >= s.o.pollInterval
+		s.setAtEOF(true)
+		if s.o.stopAtEOF {
+			s.finish(ctx)
+			return false, s.fatal
+		}
+
+		// EOF: rewind over any incomplete record so it is read again
+		// in full once the rest of it arrives.
+		n := s.scan.pending
+		if n > s.pending {
+			s.stuckPolls = 0
+		}
+		expired := s.partialExpired(n)
+		s.pending = n
+		if expired {
+			// Show an incomplete line that has stopped growing.
+			if !s.flushPartial(ctx) {
+				return false, s.fatal
+			}
+			n = 0
+		}
+		if err := s.rewind(n); err != nil {
+			return false, err
+		}
+
+		// Lines held back for ordering wait no longer than the
+		// backlog, or a poll interval without new data.
+		if idle || !s.caughtUp && s.settled() {
+			if !s.flushReorder(ctx) {
+				return false, s.fatal
+			}
+		}
+
+		if !s.caughtUp && s.settled() {
+			s.caughtUp = true
+			if !s.signalCaughtUp(ctx) {
+				return false, nil
+			}
+		}
+
+		generation := s.generation
+		if !s.o.appendOnly {
+			if _, err := s.checkFileState(); err != nil {
+				return false, err
 			}
+		}
 
-			// Reset reader to drop cached EOF so new data is visible.
-			if !reopened {
-				reader.Reset(file)
+		// The current file is drained; move on if a successor exists.
+		if s.next != nil {
+			if path := s.next(s.path); path != s.path && s.switchTo(path) {
+				return s.flushReorder(ctx), s.fatal
 			}
+		}
+		if s.generation != generation && !s.flushReorder(ctx) {
+			return false, s.fatal
+		}
+
+		s.waitForData(ctx)
+		return ctx.Err() == nil, nil
+	}
+
+	// Complete line received.
+	s.setAtEOF(false)
+	s.pending = 0
+	if s.gz != nil {
+		s.gz.release(s.scan.r.Buffered())
+	}
+	if s.beforeMarker(line) {
+		return true, nil
+	}
+	s.inFlight = s.scan.last
+	ok = s.deliver(ctx, line)
+	s.inFlight = 0
+	if !ok {
+		return false, s.fatal
+	}
+	return true, nil
+}
+
+// deliver numbers a line read from the file, hands it to the aggregator,
+// skips duplicates, puts lines in order under [WithReorder] and passes
+// the rest on to deliverFiltered. It returns false if ctx was cancelled.
+func (s *tailState) deliver(ctx context.Context, l Line) bool {
+	s.readSeq++
+	l.Seq = s.readSeq
+	s.sendingAt = s.readPos()
+	s.t.observe(l)
+	if s.duplicate(l) {
+		return true
+	}
+	if s.reordering != nil {
+		return s.reorder(ctx, l)
+	}
+	return s.deliverFiltered(ctx, l)
+}
 
-			waitForData(ctx, o)
+// deliverFiltered applies the line filters, with any surrounding context
+// lines, and sends the lines that pass. It returns false if ctx was
+// cancelled.
+//
+// A command received while blocked may discard the lines being
+// delivered, such as [Tailer.JumpToEnd]; delivery then stops early.
+func (s *tailState) deliverFiltered(ctx context.Context, l Line) bool {
+	epoch := s.epoch
+	if !s.o.filtering() {
+		return s.send(ctx, l)
+	}
+
+	if !s.o.accepts(l) {
+		if s.afterLeft > 0 {
+			s.afterLeft--
+			return s.send(ctx, l)
+		}
+		s.before.push(l)
+		return true
+	}
+
+	for i := 0; i < s.before.len(); i++ {
+		if !s.send(ctx, s.before.at(i)) {
+			return false
+		}
+		if s.epoch != epoch {
+			return true
+		}
+	}
+	s.before.reset()
+	s.afterLeft = s.o.contextAfter
+	return s.send(ctx, l)
+}
+
+// send delivers l on the Lines channel, serving commands while the
+// channel is full. Once l has been handed over, it is written to the
+// [WithTee] writer. It returns false if ctx was cancelled first, or if
+// that write failed fatally, setting s.fatal. If a command discarded
+// pending output, l is dropped.
+func (s *tailState) send(ctx context.Context, l Line) bool {
+	if s.pull {
+		if !s.tee(l) {
+			return false
+		}
+		s.queue = append(s.queue, l)
+		s.t.updateStats(func(st *Stats) { st.Lines++ })
+		return true
+	}
+
+	// Markers are never dropped: a consumer may be waiting for one.
+	drop := s.o.overflow == OverflowDrop && l.Marker == NoMarker
+	epoch := s.epoch
+	for {
+		if s.paused {
+			if !s.waitWhilePaused(ctx) {
+				return false
+			}
+			if s.epoch != epoch {
+				return true
+			}
 			continue
 		}
 
-		// Complete line received.
-		if partialLine != "" {
-			line = partialLine + line
-			partialLine = ""
+		if s.spill != nil {
+			if s.spillLine(l, s.sendingAt) {
+				return s.tee(l)
+			}
+			if s.spill.n > 0 {
+				// The spill file is full. Lines queued there go first.
+				if drop {
+					s.t.updateStats(func(st *Stats) { st.Dropped++ })
+					return true
+				}
+				if !s.sendSpilled(ctx) {
+					return false
+				}
+				if s.epoch != epoch {
+					return true
+				}
+				continue
+			}
 		}
 
-		line = strings.TrimRight(line, "\r\n")
-		if line == "" {
+		if !s.fits(l) {
+			if drop {
+				s.t.updateStats(func(st *Stats) { st.Dropped++ })
+				return true
+			}
+			if !s.waitForRoom(ctx) {
+				return false
+			}
+			if s.epoch != epoch {
+				return true
+			}
 			continue
 		}
 
-		l := Line{
-			Text: line,
-			Time: time.Now(),
+		if drop {
+			select {
+			case s.t.lines <- l:
+				s.t.recordSent(l)
+				return s.tee(l)
+			default:
+				s.t.updateStats(func(st *Stats) { st.Dropped++ })
+			}
+			return true
 		}
 
 		select {
-		case t.lines <- l:
+		case s.t.lines <- l:
+			s.t.recordSent(l)
+			return s.tee(l)
+		case c := <-s.t.cmds:
+			s.run(c)
+			if s.epoch != epoch {
+				return true
+			}
 		case <-ctx.Done():
-			return nil
+			return false
 		}
 	}
 }
 
-// checkFileState detects file truncation and rotation, adjusting the
-// file handle and reader as needed. Returns true for reopened if the
-// file was rotated to a new inode.
-func checkFileState(file *os.File, reader *bufio.Reader, fileID fileIdentity, path string) (*os.File, *bufio.Reader, fileIdentity, bool, error) {
-	// Check truncation: current position beyond file size.
-	currentPos, err := file.Seek(0, io.SeekCurrent)
-	if err != nil {
-		return file, reader, fileID, false, fmt.Errorf("seek error: %w", err)
+// rewind moves the file position back over the n bytes of an incomplete
+// record, plus anything still buffered, and resets the reader so the
+// next read starts at the beginning of that record, which the scanner
+// then no longer counts as consumed.
+func (s *tailState) rewind(n int) error {
+	back := int64(n + s.scan.r.Buffered())
+	switch {
+	case s.gz != nil:
+		s.gz.unread(back)
+		s.scan.r.Reset(s.gz)
+	case back == 0:
+		// Nothing to go back over; this also spares unseekable files.
+		s.scan.r.Reset(fileSource(s.file, &s.o))
+	default:
+		if _, err := s.file.Seek(-back, io.SeekCurrent); err != nil {
+			return s.fail("seek", err)
+		}
+		s.scan.r.Reset(fileSource(s.file, &s.o))
+	}
+	s.scan.pending, s.scan.partial = 0, nil
+	return nil
+}
+
+// newReader returns a reader for file, from its current position, that
+// decompresses it under [WithGzipInput].
+func (s *tailState) newReader(file *os.File) *bufio.Reader {
+	s.noDeadline = false
+	if s.o.gzip {
+		s.gz = newGzipSource(file)
+		s.expectHeader()
+		return bufio.NewReaderSize(s.gz, s.o.bufSize)
 	}
+	s.expectHeader()
+	return bufio.NewReaderSize(fileSource(file, &s.o), s.o.bufSize)
+}
 
-	stat, err := file.Stat()
+// resetReader discards buffered input after the file was repositioned.
+func (s *tailState) resetReader() {
+	if s.o.gzip {
+		s.gz = newGzipSource(s.file)
+		s.scan.r.Reset(s.gz)
+	} else {
+		s.scan.r.Reset(fileSource(s.file, &s.o))
+	}
+	s.expectHeader()
+}
+
+// expectHeader arranges for the [WithSkipHeaderBytes] header to be
+// skipped if reading starts at the beginning of the file.
+func (s *tailState) expectHeader() {
+	s.scan.header = 0
+	if pos, err := s.position(); err == nil && pos == 0 {
+		s.scan.header = s.o.skipHeader
+	}
+}
+
+// position returns the offset in the file up to which it has been read.
+// For compressed input that is the end of the last member decoded.
+func (s *tailState) position() (int64, error) {
+	if s.gz != nil {
+		return s.gz.off, nil
+	}
+	return s.file.Seek(0, io.SeekCurrent)
+}
+
+// signalCaughtUp announces that the initial backlog has been read, via the
+// [Tailer.CaughtUp] channel, the [WithCaughtUpMarker] callback and, when
+// enabled, an in-band marker line. It returns false if ctx was cancelled
+// while delivering the marker.
+func (s *tailState) signalCaughtUp(ctx context.Context) bool {
+	close(s.t.caughtUp)
+	if s.o.onCaughtUp != nil {
+		s.o.onCaughtUp()
+	}
+	s.announce(LifecycleEvent{Type: LifecycleCaughtUp})
+	if !s.o.inBandMarkers {
+		return true
+	}
+	return s.send(ctx, Line{Time: time.Now(), Marker: CaughtUp})
+}
+
+// settled reports whether the tailer has been at the end of the file for
+// the [WithCaughtUpStable] period.
+func (s *tailState) settled() bool {
+	return s.o.caughtUpStable <= 0 || time.Since(s.eofSince) >= s.o.caughtUpStable
+}
+
+// checkFileState detects file rotation, truncation and stuck handles,
+// adjusting the file handle and reader as needed. Returns true for
+// reopened if a new handle was opened and the reader replaced.
+func (s *tailState) checkFileState() (bool, error) {
+	// This only runs once a read has hit EOF, and the position is taken
+	// before the size, so data appended concurrently by a writer can only
+	// make the size larger than the position, never smaller.
+	currentPos, err := s.position()
 	if err != nil {
-		return file, reader, fileID, false, fmt.Errorf("stat error: %w", err)
+		return false, s.fail("seek", err)
 	}
 
+	stat, err := s.file.Stat()
+	if err != nil {
+		return false, s.fail("stat", err)
+	}
 	if stat.Size() < currentPos {
-		// File was truncated (e.g. logrotate copytruncate). Seek to start.
-		if _, err := file.Seek(0, io.SeekStart); err != nil {
-			return file, reader, fileID, false, fmt.Errorf("seek after truncation: %w", err)
+		// Confirm with a second stat rather than act on a size that a
+		// filesystem reported before an append became visible.
+		if stat, err = s.file.Stat(); err != nil {
+			return false, s.fail("stat", err)
 		}
-		reader.Reset(file)
-		return file, reader, fileID, false, nil
 	}
 
-	// Check rotation: file at path has a different inode.
-	pathInfo, err := os.Stat(path)
-	if err != nil {
+	// Check rotation first: file at path has a different inode, or
+	// identity under WithIdentityFunc. s.fileID comes from the handle,
+	// whose device may differ from the path's. A file that was both
+	// truncated and replaced since the last poll, as by tooling that
+	// empties the old file before renaming a new one over it, is
+	// followed to the new file rather than re-read from the top.
+	var pathInfo os.FileInfo
+	var pathErr error
+	if s.o.detectRotation {
+		pathInfo, pathErr = os.Stat(s.path)
+		if pathErr == nil {
+			justRotated := s.justRotated
+			s.justRotated = false
+			if s.replacedBy(pathInfo) {
+				return s.rotate(stat, currentPos, justRotated)
+			}
+		}
+	}
+
+	// Check truncation: current position beyond file size.
+	shrunk := s.o.restartOnShrink && stat.Size() < s.lastSize
+	s.lastSize = stat.Size()
+	if stat.Size() < currentPos || shrunk || s.o.pathTruncationCheck && s.pathTruncated(currentPos) {
+		// File was truncated (e.g. logrotate copytruncate), or rewritten
+		// smaller under WithRestartOnShrink.
+		return false, s.restart()
+	}
+	if n := s.o.maxFileSize; n > 0 && stat.Size() > n {
+		return false, s.fail("stat", fmt.Errorf("%w: %d bytes, limit %d", ErrFileTooLarge, stat.Size(), n))
+	}
+
+	if !s.o.detectRotation {
+		return false, nil
+	}
+	if pathErr != nil {
 		// File may have been removed temporarily during rotation.
-		// Not fatal — we'll retry on next poll.
-		return file, reader, fileID, false, nil
+		// Not fatal — we'll retry on next poll, unless another
+		// candidate path can take over.
+		return s.failover(), nil
 	}
 
-	newID := getFileIdentity(pathInfo)
-	if newID != fileID && newID != (fileIdentity{}) {
-		// File was rotated. Open the new file.
-		newFile, err := os.Open(path)
-		if err != nil {
-			return file, reader, fileID, false, nil
+	if isSymlink(s.path) != s.isLink {
+		// The path turned from a file into a symbolic link to the same
+		// file, or back. Reopen it so that the handle matches the path.
+		if !s.reopen(currentPos) {
+			return false, nil
 		}
-		file.Close()
-		newReader := bufio.NewReader(newFile)
+		s.t.updateStats(func(st *Stats) { st.Reopens++ })
+		s.emit(EventReopened)
+		return true, nil
+	}
+
+	s.drainSince = time.Time{}
+
+	// Check for a stuck handle: the path keeps growing beyond our
+	// position but reads on our handle make no progress.
+	// Compressed input leaves an incomplete member unread, and a file
+	// written through a mapping is sized ahead of its data, so the path
+	// legitimately reports more data than we have read.
+	if s.o.stuckPolls <= 0 || s.gz != nil || s.o.writerMmap || pathInfo.Size() <= currentPos+int64(s.pending) {
+		s.stuckPolls = 0
+		return false, nil
+	}
+	s.stuckPolls++
+	if s.stuckPolls < s.o.stuckPolls {
+		return false, nil
+	}
+	if !s.reopen(currentPos) {
+		return false, nil
+	}
+	s.t.updateStats(func(st *Stats) { st.Reopens++ })
+	s.emit(EventReopened)
+	return true, nil
+}
 
-		newInfo, err := newFile.Stat()
-		if err != nil {
-			newFile.Close()
-			return file, reader, fileID, false, fmt.Errorf("stat new file: %w", err)
+// rotate switches to the file that replaced the current one at the path,
+// once the current one has been drained. stat describes the current
+// handle, read up to pos; justRotated reports whether the previous poll
+// switched files. Returns true for reopened if it switched.
+func (s *tailState) rotate(stat os.FileInfo, pos int64, justRotated bool) (bool, error) {
+	// Give a file we just rotated to at least one poll, and the
+	// configured cooldown, to receive data before rotating again,
+	// so rapid successive rotations cannot make us flap.
+	if justRotated || time.Since(s.lastRotation) < s.o.rotationCooldown {
+		return false, nil
+	}
+
+	// Drain the old file first: data may have been appended to it
+	// after our last read but before it was replaced, as with a
+	// writer that renames a new file over the old one. The size of
+	// a file written through a mapping says nothing about that.
+	// A writer that keeps the old file open and growing is given
+	// up on after WithRotationDrainTimeout. An old file truncated
+	// below pos has nothing left to drain.
+	undrained := stat.Size() - (pos + int64(s.pending))
+	if s.gz == nil && !s.o.writerMmap && undrained > 0 {
+		if s.drainSince.IsZero() {
+			s.drainSince = time.Now()
+		}
+		if d := s.o.rotationDrainTimeout; d <= 0 || time.Since(s.drainSince) < d {
+			return false, nil
 		}
+		s.t.updateStats(func(st *Stats) { st.DroppedOnRotation += undrained })
+	}
 
-		return newFile, newReader, getFileIdentity(newInfo), true, nil
+	// File was rotated. Open the new file.
+	if !s.reopen(0) {
+		return false, nil
+	}
+	if s.o.fromStartOnce && !s.o.gzip {
+		if err := s.skipExisting(); err != nil {
+			return false, err
+		}
 	}
+	s.pending = 0
+	s.generation++
+	s.justRotated = true
+	s.drainSince = time.Time{}
+	s.lastRotation = time.Now()
+	s.t.updateStats(func(st *Stats) {
+		st.Rotations++
+		st.LastRotation = s.lastRotation
+	})
+	s.emit(EventRotated)
+	return true, nil
+}
 
-	return file, reader, fileID, false, nil
+// pathTruncated reports whether a stat of the path, rather than of the
+// handle, shows the current file to be shorter than pos. Overlay
+// filesystems can report the old size on the handle for a while after a
+// truncation; see [WithPathTruncationCheck]. A file replaced at the path
+// is left to rotation detection.
+func (s *tailState) pathTruncated(pos int64) bool {
+	info, err := os.Stat(s.path)
+	if err != nil || s.replacedBy(info) {
+		return false
+	}
+	return info.Size() < pos
+}
+
+// restartIfShrunk restarts from the top if the file is now smaller than
+// at the previous poll.
+func (s *tailState) restartIfShrunk() error {
+	stat, err := s.file.Stat()
+	if err != nil {
+		return s.fail("stat", err)
+	}
+	shrunk := stat.Size() < s.lastSize
+	s.lastSize = stat.Size()
+	if !shrunk {
+		return nil
+	}
+	return s.restart()
+}
+
+// restart seeks back to the start of a truncated file.
+func (s *tailState) restart() error {
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return s.fail("seek after truncation", err)
+	}
+	s.resetReader()
+	s.pending = 0
+	s.stuckPolls = 0
+	s.generation++
+	s.t.updateStats(func(st *Stats) {
+		st.Truncations++
+		st.LastTruncation = time.Now()
+	})
+	s.emit(EventTruncated)
+	return nil
+}
+
+// reopen replaces the current handle with a fresh one opened at path,
+// positioned at offset, or at the start if offset lies beyond the end of
+// the new file. It reports false, leaving the current handle in place,
+// if the path could not be opened.
+func (s *tailState) reopen(offset int64) bool {
+	newFile, err := openShared(s.path)
+	if err != nil {
+		return false
+	}
+
+	newInfo, err := newFile.Stat()
+	if err != nil {
+		newFile.Close()
+		return false
+	}
+
+	if offset > newInfo.Size() {
+		offset = 0
+	}
+	if _, err := newFile.Seek(offset, io.SeekStart); err != nil {
+		newFile.Close()
+		return false
+	}
+
+	s.file.Close()
+	s.file = newFile
+	s.scan.r = s.newReader(newFile)
+	s.identify(newInfo)
+	s.stuckPolls = 0
+	s.lastSize = 0
+	return true
+}
+
+// skipExisting moves past the data a file just rotated to already holds,
+// for [WithFromStartOnce].
+func (s *tailState) skipExisting() error {
+	info, err := s.file.Stat()
+	if err != nil {
+		return s.fail("stat", err)
+	}
+	end := info.Size()
+	if s.o.writerMmap {
+		if end, err = writtenSize(s.file, end); err != nil {
+			return s.fail("read", err)
+		}
+	}
+	if _, err := s.file.Seek(end, io.SeekStart); err != nil {
+		return s.fail("seek", err)
+	}
+	s.resetReader()
+	return nil
+}
+
+// failover switches to the first path among the primary and the
+// [WithFallbackPaths] candidates, other than the current one, that can be
+// opened. It reports whether a switch happened.
+func (s *tailState) failover() bool {
+	if len(s.o.fallbackPaths) == 0 {
+		return false
+	}
+	for _, path := range append([]string{s.primary}, s.o.fallbackPaths...) {
+		if path != s.path && s.switchTo(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// switchTo replaces the current handle with path, read from the start.
+// It reports false, leaving the current handle in place, if path could
+// not be opened.
+func (s *tailState) switchTo(path string) bool {
+	file, err := openShared(path)
+	if err != nil {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return false
+	}
+
+	old := s.path
+	s.file.Close()
+	s.file = file
+	s.scan.r = s.newReader(file)
+	s.path = path
+	s.identify(info)
+	s.indexPath()
+	s.pending = 0
+	s.stuckPolls = 0
+	s.lastSize = 0
+	s.generation++
+
+	s.emitSwitched(old)
+	return true
+}
+
+// emit delivers an event to the [WithEventHandler] callback and the
+// [WithLifecycleEvents] channel, if any.
+func (s *tailState) emit(typ EventType) {
+	if s.o.onEvent != nil {
+		s.o.onEvent(Event{
+			Type:     typ,
+			Path:     s.path,
+			Time:     time.Now(),
+			Position: s.eventToken(),
+		})
+	}
+	s.announce(LifecycleEvent{Type: typ.lifecycle()})
+}
+
+// emitSwitched emits an [EventSwitched] event for a move from the path
+// old to the current one.
+func (s *tailState) emitSwitched(old string) {
+	if s.o.onEvent != nil {
+		s.o.onEvent(Event{
+			Type:     EventSwitched,
+			Path:     s.path,
+			OldPath:  old,
+			Time:     time.Now(),
+			Position: s.eventToken(),
+		})
+	}
+	s.announce(LifecycleEvent{Type: LifecycleSwitched, OldPath: old})
+}
+
+// eventToken returns the [Event].Position token: the current position,
+// or the zero Token for compressed input.
+func (s *tailState) eventToken() Token {
+	if s.o.gzip {
+		return Token{}
+	}
+	tok, err := s.token()
+	if err != nil {
+		return Token{}
+	}
+	return tok
 }
 
 // waitForData blocks until either the notify channel fires, the poll
-// interval elapses, or the context is cancelled.
-func waitForData(ctx context.Context, o options) {
-	if o.notify != nil {
-		// Wait for notification with poll interval as fallback timeout.
-		timer := time.NewTimer(o.pollInterval)
-		defer timer.Stop()
+// interval elapses, a command arrives, or the context is cancelled. With
+// [WithManualPoll], a receive from its trigger replaces both the first two.
+func (s *tailState) waitForData(ctx context.Context) {
+	// Lines spilled to disk are delivered while there is nothing to read.
+	out, spilled := s.spilled()
+
+	if s.o.manualPoll != nil {
+		// Read only when the caller says so.
 		select {
-		case <-o.notify:
-		case <-timer.C:
+		case <-s.o.manualPoll:
+		case out <- spilled:
+			s.sentSpilled(spilled)
+		case c := <-s.t.cmds:
+			s.run(c)
 		case <-ctx.Done():
 		}
 		return
 	}
 
+	// Under WithScheduler its ticks replace the timer.
+	var timer *time.Timer
+	var timeout <-chan time.Time
+	if s.tick == nil {
+		timer = time.NewTimer(s.waitInterval())
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	if s.o.notify != nil {
+		// Wait for notification with poll interval as fallback timeout.
+		for {
+			select {
+			case <-s.o.notify:
+				// A notification soon after the one that woke the last
+				// read is part of the same burst. Read once more when
+				// the window ends, or on the next tick, instead of once
+				// per notification.
+				if w := s.o.notifyCoalesce; w > 0 && time.Since(s.lastNotify) < w {
+					if timer != nil {
+						timer.Reset(time.Until(s.lastNotify.Add(w)))
+					}
+					continue
+				}
+				s.lastNotify = time.Now()
+			case <-timeout:
+			case <-s.tick:
+			case out <- spilled:
+				s.sentSpilled(spilled)
+			case c := <-s.t.cmds:
+				s.run(c)
+			case <-ctx.Done():
+			}
+			return
+		}
+	}
+
 	// Pure polling fallback.
-	timer := time.NewTimer(o.pollInterval)
-	defer timer.Stop()
 	select {
-	case <-timer.C:
+	case <-timeout:
+	case <-s.tick:
+	case out <- spilled:
+		s.sentSpilled(spilled)
+	case c := <-s.t.cmds:
+		s.run(c)
 	case <-ctx.Done():
 	}
 }
 
-func openFile(path string, o options) (*os.File, *bufio.Reader, fileIdentity, error) {
-	file, err := os.Open(path)
+// waitInterval returns how long to wait for data before checking the
+// file again: the poll interval, capped by [WithReadTimeout].
+func (s *tailState) waitInterval() time.Duration {
+	if s.o.readTimeout > 0 {
+		return min(s.o.pollInterval, s.o.readTimeout)
+	}
+	return s.o.pollInterval
+}
+
+// openFile opens path and positions it where tailing should begin. The
+// returned torn flag reports that this position lies inside a line, whose
+// remainder the tailer must skip.
+func openFile(path string, o options) (*os.File, *bufio.Reader, os.FileInfo, bool, error) {
+	file, err := openShared(path)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	info, err := file.Stat()
 	if err != nil {
-		return nil, nil, fileIdentity{}, err
+		file.Close()
+		return nil, nil, nil, false, err
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, nil, nil, false, fmt.Errorf("%w: %s", ErrIsDirectory, path)
 	}
 
-	if !o.fromStart {
-		if _, err := file.Seek(0, io.SeekEnd); err != nil {
+	size := info.Size()
+	if o.writerMmap {
+		if size, err = writtenSize(file, size); err != nil {
 			file.Close()
-			return nil, nil, fileIdentity{}, err
+			return nil, nil, nil, false, err
 		}
 	}
-
-	info, err := file.Stat()
+	torn, err := seekStart(file, size, o)
 	if err != nil {
 		file.Close()
-		return nil, nil, fileIdentity{}, err
+		return nil, nil, nil, false, err
+	}
+
+	reader := bufio.NewReaderSize(fileSource(file, &o), o.bufSize)
+	return file, reader, info, torn, nil
+}
+
+// seekStart moves file to the position tailing should begin at and
+// reports whether that position lies inside a line.
+func seekStart(file *os.File, size int64, o options) (bool, error) {
+	var pos int64
+	switch {
+	case o.startPercent >= 0:
+		pos = int64(float64(size) * min(o.startPercent, 1))
+	case o.initialBytes > 0:
+		pos = max(size-o.initialBytes, 0)
+	case o.fromStart:
+		return false, nil
+	case o.writerMmap:
+		// The end of the written data, not of the file.
+		pos = size
+	default:
+		_, err := file.Seek(0, io.SeekEnd)
+		return false, err
 	}
 
-	reader := bufio.NewReaderSize(file, o.bufSize)
-	return file, reader, getFileIdentity(info), nil
+	if f, ok := o.framer.(fixedFramer); ok {
+		// Records have no delimiter to align to, but a known size.
+		pos -= pos % int64(f.size)
+	}
+	if _, err := file.Seek(pos, io.SeekStart); err != nil {
+		return false, err
+	}
+	if _, ok := o.framer.(fixedFramer); ok || pos == 0 {
+		return false, nil
+	}
+
+	// Starting right after a newline needs no alignment.
+	var prev [1]byte
+	if _, err := file.ReadAt(prev[:], pos-1); err != nil {
+		return false, err
+	}
+	return prev[0] != '\n', nil
 }
@@ -1,10 +1,15 @@
 package tailf
 
 import (
+	"bufio"
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -162,6 +167,10 @@ func TestFollowTruncation(t *testing.T) {
 
 	// Truncate file (simulates logrotate copytruncate).
 	time.Sleep(200 * time.Millisecond)
+	if st := tailer.Stats(); !st.LastTruncation.IsZero() {
+		t.Errorf("LastTruncation = %v before any truncation", st.LastTruncation)
+	}
+	truncated := time.Now()
 	if err := os.Truncate(path, 0); err != nil {
 		t.Fatal(err)
 	}
@@ -185,6 +194,9 @@ func TestFollowTruncation(t *testing.T) {
 	case <-ctx.Done():
 		t.Fatal("timed out waiting for line after truncation")
 	}
+	if st := tailer.Stats(); st.LastTruncation.Before(truncated) {
+		t.Errorf("LastTruncation = %v, want after %v", st.LastTruncation, truncated)
+	}
 
 	cancel()
 	<-tailer.Done()
@@ -218,6 +230,7 @@ func TestFollowRotation(t *testing.T) {
 
 	// Simulate rotation: rename old file and create new one.
 	time.Sleep(200 * time.Millisecond)
+	rotatedAt := time.Now()
 	rotated := filepath.Join(tmp, "test.log.1")
 	if err := os.Rename(path, rotated); err != nil {
 		t.Fatal(err)
@@ -234,11 +247,74 @@ func TestFollowRotation(t *testing.T) {
 	case <-ctx.Done():
 		t.Fatal("timed out waiting for line after rotation")
 	}
+	if st := tailer.Stats(); RotationDetectionSupported() && st.LastRotation.Before(rotatedAt) {
+		t.Errorf("LastRotation = %v, want after %v", st.LastRotation, rotatedAt)
+	}
 
 	cancel()
 	<-tailer.Done()
 }
 
+func TestFollowFromStartOnce(t *testing.T) {
+	if !RotationDetectionSupported() {
+		t.Skip("no file identities on this platform")
+	}
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+	if err := os.WriteFile(path, []byte("old 1\nold 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rotated := make(chan struct{}, 1)
+	tailer, err := Follow(ctx, path,
+		WithFromStartOnce(true),
+		WithPollInterval(10*time.Millisecond),
+		WithEventHandler(func(e Event) {
+			if e.Type == EventRotated {
+				rotated <- struct{}{}
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"old 1", "old 2"} {
+		if got := nextLine(ctx, t, tailer); got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	}
+
+	// The new file's history belongs to someone else; only what is
+	// written after the rotation is noticed gets delivered.
+	if err := os.Rename(path, filepath.Join(tmp, "test.log.1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("history\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-rotated:
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the rotation")
+	}
+	appendLine(t, path, "new")
+	if got := nextLine(ctx, t, tailer); got != "new" {
+		t.Errorf("got %q, want %q", got, "new")
+	}
+}
+
+func TestFromStartOnceDisabled(t *testing.T) {
+	o := defaults()
+	WithFromStart(true)(&o)
+	WithFromStartOnce(false)(&o)
+	if !o.fromStart || o.fromStartOnce {
+		t.Errorf("fromStart = %v, fromStartOnce = %v; want true, false", o.fromStart, o.fromStartOnce)
+	}
+}
+
 func TestFollowPartialLines(t *testing.T) {
 	tmp := t.TempDir()
 	path := filepath.Join(tmp, "test.log")
@@ -460,3 +536,1400 @@ func TestFollowLineTime(t *testing.T) {
 	cancel()
 	<-tailer.Done()
 }
+
+func TestFollowLineTimeMonotonic(t *testing.T) {
+	for _, spill := range []bool{false, true} {
+		t.Run(fmt.Sprintf("spill=%v", spill), func(t *testing.T) {
+			tmp := t.TempDir()
+			path := filepath.Join(tmp, "test.log")
+			const n = 1000
+			writeNumbered(t, path, n)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			opts := []Option{WithFromStart(true)}
+			if spill {
+				// Lines read while nobody receives go through the spill
+				// file, which encodes them.
+				opts = append(opts, WithSpillToDisk(tmp, 0))
+			}
+			tailer, err := Follow(ctx, path, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if spill {
+				<-tailer.CaughtUp()
+			}
+
+			var prev Line
+			for i := range n {
+				var l Line
+				select {
+				case l = <-tailer.Lines():
+				case <-ctx.Done():
+					t.Fatalf("timed out after %d lines", i)
+				}
+				if !strings.Contains(l.Time.String(), " m=") {
+					t.Fatalf("line %d: time %v has no monotonic reading", i, l.Time)
+				}
+				if l.Seq != prev.Seq+1 || l.Time.Before(prev.Time) {
+					t.Fatalf("line %d: seq %d at %v after seq %d at %v", i, l.Seq, l.Time, prev.Seq, prev.Time)
+				}
+				prev = l
+			}
+		})
+	}
+}
+
+func TestFollowCaughtUpMarker(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("history\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	called := make(chan struct{})
+	tailer, err := Follow(ctx, path,
+		WithFromStart(true),
+		WithInBandMarkers(true),
+		WithCaughtUpMarker(func() { close(called) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []Line
+	for i := 0; i < 2; i++ {
+		select {
+		case line := <-tailer.Lines():
+			got = append(got, line)
+		case <-ctx.Done():
+			t.Fatalf("timed out after receiving %d lines", len(got))
+		}
+	}
+
+	if got[0].Text != "history" || got[0].Marker != NoMarker {
+		t.Errorf("first line: got %+v, want history line", got[0])
+	}
+	if got[1].Marker != CaughtUp || got[1].Text != "" {
+		t.Errorf("second line: got %+v, want CaughtUp marker", got[1])
+	}
+
+	select {
+	case <-called:
+	case <-ctx.Done():
+		t.Fatal("caught-up callback not invoked")
+	}
+	select {
+	case <-tailer.CaughtUp():
+	case <-ctx.Done():
+		t.Fatal("CaughtUp channel not closed")
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("live\n")
+	f.Close()
+
+	select {
+	case line := <-tailer.Lines():
+		if line.Text != "live" || line.Marker != NoMarker {
+			t.Errorf("got %+v, want live line", line)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for live line")
+	}
+
+	cancel()
+	<-tailer.Done()
+}
+
+func TestFollowCaughtUpMarkerOverflowDrop(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	writeNumbered(t, path, 2*lineBuffer)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path,
+		WithFromStart(true),
+		WithInBandMarkers(true),
+		WithOverflowPolicy(OverflowDrop),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-tailer.CaughtUp()
+
+	// The backlog overflowed the channel, but the marker still arrives.
+	for marked := false; !marked; {
+		select {
+		case line := <-tailer.Lines():
+			marked = line.Marker == CaughtUp
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for the CaughtUp marker")
+		}
+	}
+	if got := tailer.Stats().Dropped; got != lineBuffer {
+		t.Errorf("got %d dropped, want %d", got, lineBuffer)
+	}
+	cancel()
+	<-tailer.Done()
+}
+
+func TestCheckFileStateReopensStuckHandle(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+	if err := os.WriteFile(path, []byte("fresh data\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a stuck handle: the state believes it is reading path,
+	// but its handle is an empty file that never grows.
+	stuck, err := os.Create(filepath.Join(tmp, "stuck"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var events []Event
+	o := defaults()
+	o.stuckPolls = 3
+	o.onEvent = func(e Event) { events = append(events, e) }
+
+	s := &tailState{
+		t:      &Tailer{},
+		o:      o,
+		path:   path,
+		file:   stuck,
+		scan:   lineScanner{r: bufio.NewReader(stuck)},
+		fileID: getFileIdentity(info),
+	}
+	defer s.close()
+
+	for i := 1; i <= 3; i++ {
+		reopened, err := s.checkFileState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := i == 3; reopened != want {
+			t.Fatalf("poll %d: reopened = %v, want %v", i, reopened, want)
+		}
+	}
+
+	line, err := s.scan.r.ReadString('\n')
+	if err != nil || line != "fresh data\n" {
+		t.Errorf("after reopen read %q, %v; want %q", line, err, "fresh data\n")
+	}
+	if got := s.t.Stats().Reopens; got != 1 {
+		t.Errorf("Reopens = %d, want 1", got)
+	}
+	if len(events) != 1 || events[0].Type != EventReopened {
+		t.Errorf("events = %v, want one EventReopened", events)
+	}
+}
+
+// openFDs returns the number of open file descriptors of the test
+// process, skipping the test where that cannot be determined.
+func openFDs(t *testing.T) int {
+	t.Helper()
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Skip("cannot count open file descriptors on this platform")
+	}
+	return len(entries)
+}
+
+func TestFollowFuncPanic(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("boom\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	before := openFDs(t)
+
+	err := FollowFunc(ctx, path, func(line Line) {
+		panic("callback failed on " + line.Text)
+	}, WithFromStart(true))
+
+	if err == nil {
+		t.Fatal("expected error from panicking callback")
+	}
+	if !strings.Contains(err.Error(), "tailf:") || !strings.Contains(err.Error(), "callback failed on boom") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if ctx.Err() != nil {
+		t.Error("FollowFunc should return before the parent context expires")
+	}
+	if after := openFDs(t); after != before {
+		t.Errorf("open file descriptors: got %d, want %d", after, before)
+	}
+}
+
+func TestFollowReleasesFDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, []byte("line\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	before := openFDs(t)
+
+	// Start and stop 1000 tailers, 50 at a time, some stopped with a
+	// line undelivered and some idle at the end of the file.
+	const batch = 50
+	for round := 0; round < 1000/batch; round++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		tailers := make([]*Tailer, batch)
+		for i := range tailers {
+			tailer, err := Follow(ctx, path, WithFromStart(i%2 == 0), WithPollInterval(time.Millisecond))
+			if err != nil {
+				cancel()
+				t.Fatal(err)
+			}
+			tailers[i] = tailer
+		}
+		if tailers[0].Closed() {
+			t.Error("Closed reported true for a running tailer")
+		}
+		cancel()
+		for _, tailer := range tailers {
+			<-tailer.Done()
+			if !tailer.Closed() {
+				t.Fatal("Closed reported false after Done")
+			}
+		}
+	}
+
+	if after := openFDs(t); after != before {
+		t.Errorf("open file descriptors: got %d, want %d", after, before)
+	}
+}
+
+func TestFollowFallbackPathAtStart(t *testing.T) {
+	tmp := t.TempDir()
+	primary := filepath.Join(tmp, "missing", "app.log")
+	fallback := filepath.Join(tmp, "app.log")
+
+	if err := os.WriteFile(fallback, []byte("from fallback\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, primary, WithFromStart(true), WithFallbackPaths(fallback))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case line := <-tailer.Lines():
+		if line.Text != "from fallback" {
+			t.Errorf("got %q, want %q", line.Text, "from fallback")
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+
+	cancel()
+	<-tailer.Done()
+}
+
+func TestFollowFallbackPathTakesOver(t *testing.T) {
+	tmp := t.TempDir()
+	primary := filepath.Join(tmp, "primary.log")
+	fallback := filepath.Join(tmp, "fallback.log")
+
+	if err := os.WriteFile(primary, []byte("from primary\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fallback, []byte("from fallback\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	events := make(chan Event, 4)
+	tailer, err := Follow(ctx, primary,
+		WithFromStart(true),
+		WithFallbackPaths(fallback),
+		WithEventHandler(func(e Event) { events <- e }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case line := <-tailer.Lines():
+		if line.Text != "from primary" {
+			t.Errorf("got %q, want %q", line.Text, "from primary")
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for primary line")
+	}
+
+	if err := os.Remove(primary); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case line := <-tailer.Lines():
+		if line.Text != "from fallback" {
+			t.Errorf("got %q, want %q", line.Text, "from fallback")
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for fallback line")
+	}
+
+	select {
+	case e := <-events:
+		if e.Type != EventSwitched || e.OldPath != primary || e.Path != fallback {
+			t.Errorf("got event %+v, want switch from primary to fallback", e)
+		}
+	default:
+		t.Error("expected a switch event")
+	}
+
+	cancel()
+	<-tailer.Done()
+}
+
+func TestPending(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("a\nb\nc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFromStart(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-tailer.CaughtUp():
+	case <-ctx.Done():
+		t.Fatal("timed out waiting to catch up")
+	}
+	if got := tailer.Pending(); got != 3 {
+		t.Errorf("Pending before receiving = %d, want 3", got)
+	}
+
+	<-tailer.Lines()
+	if got := tailer.Pending(); got != 2 {
+		t.Errorf("Pending after one receive = %d, want 2", got)
+	}
+
+	cancel()
+	<-tailer.Done()
+}
+
+func TestFollowDirectory(t *testing.T) {
+	_, err := Follow(context.Background(), t.TempDir())
+	if !errors.Is(err, ErrIsDirectory) {
+		t.Fatalf("got %v, want ErrIsDirectory", err)
+	}
+	if !strings.HasPrefix(err.Error(), "tailf:") {
+		t.Errorf("error should be prefixed with 'tailf:', got: %v", err)
+	}
+}
+
+func TestFollowRapidRotation(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("gen 0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path,
+		WithFromStart(true),
+		WithPollInterval(20*time.Millisecond),
+		WithRotationCooldown(100*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case line := <-tailer.Lines():
+		if line.Text != "gen 0" {
+			t.Errorf("got %q, want %q", line.Text, "gen 0")
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for initial line")
+	}
+
+	// Rotate twice in quick succession; each new file gets its content
+	// shortly after creation, as a logger reopening its file would.
+	for gen := 1; gen <= 2; gen++ {
+		if err := os.Rename(path, fmt.Sprintf("%s.%d", path, gen)); err != nil {
+			t.Fatal(err)
+		}
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(30 * time.Millisecond)
+		fmt.Fprintf(f, "gen %d\n", gen)
+		f.Close()
+	}
+
+	// Lines from the intermediate file may or may not be seen, depending
+	// on timing, but the tailer must settle on the latest file and
+	// deliver its content exactly once.
+	for {
+		select {
+		case line := <-tailer.Lines():
+			if line.Text == "gen 2" {
+				if got := tailer.Stats().Rotations; got < 1 || got > 2 {
+					t.Errorf("Rotations = %d, want 1 or 2", got)
+				}
+				cancel()
+				<-tailer.Done()
+				return
+			}
+			if line.Text != "gen 1" {
+				t.Errorf("unexpected line %q", line.Text)
+			}
+		case <-ctx.Done():
+			t.Fatal("tailer did not settle on the latest file")
+		}
+	}
+}
+
+func TestFollowStartAtPercent(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		percent float64
+		want    []string
+	}{
+		// 60% of 20 bytes lands inside "cccccc", which is skipped.
+		{"mid line", "aaaa\nbbbb\ncccccc\ndd\n", 0.6, []string{"dd"}},
+		// 50% of 10 bytes lands right after a newline.
+		{"line boundary", "aaaa\nbbbb\n", 0.5, []string{"bbbb"}},
+		{"clamped low", "aaaa\nbbbb\n", -3, []string{"aaaa", "bbbb"}},
+		{"clamped high", "aaaa\nbbbb\n", 7, nil},
+		{"empty file", "", 0.9, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "test.log")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+			defer cancel()
+
+			tailer, err := Follow(ctx, path, WithStartAtPercent(tt.percent))
+			if err != nil {
+				t.Fatal(err)
+			}
+			<-tailer.CaughtUp()
+			cancel()
+
+			var got []string
+			for line := range tailer.Lines() {
+				got = append(got, line.Text)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFollowAlignToLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	// 60% of 20 bytes lands after "cc" in "cccccc".
+	if err := os.WriteFile(path, []byte("aaaa\nbbbb\ncccccc\ndd\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts []Option
+		want []string // a trailing "*" marks a partial line
+	}{
+		{"aligned", []Option{WithAlignToLine(true)}, []string{"dd"}},
+		{"torn", []Option{WithAlignToLine(false)}, []string{"cccc*", "dd"}},
+		{"torn mmap", []Option{WithAlignToLine(false), WithMmap(true)}, []string{"cccc*", "dd"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+			defer cancel()
+
+			opts := append([]Option{WithStartAtPercent(0.6)}, tt.opts...)
+			tailer, err := Follow(ctx, path, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			<-tailer.CaughtUp()
+			cancel()
+
+			var got []string
+			for line := range tailer.Lines() {
+				if line.Partial {
+					line.Text += "*"
+				}
+				got = append(got, line.Text)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFollowInitialBytes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	var content strings.Builder
+	for i := range 100 {
+		fmt.Fprintf(&content, "line %02d\n", i) // 8 bytes each
+	}
+	if err := os.WriteFile(path, []byte(content.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		n     int64
+		first string
+		count int
+	}{
+		{"line boundary", 24, "line 97", 3},
+		{"mid line", 20, "line 98", 2},
+		{"larger than file", 1 << 20, "line 00", 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+			defer cancel()
+
+			tailer, err := Follow(ctx, path, WithInitialBytes(tt.n), WithPollInterval(10*time.Millisecond))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for line := range tailer.Lines() {
+				got = append(got, line.Text)
+				if line.Text == "line 99" {
+					cancel()
+				}
+			}
+			if len(got) != tt.count || got[0] != tt.first || got[len(got)-1] != "line 99" {
+				t.Errorf("got %d lines %q..., want %d from %q", len(got), got[:min(len(got), 3)], tt.count, tt.first)
+			}
+		})
+	}
+}
+
+func TestCheckFileStateDrainsReplacedFile(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "config.log")
+
+	if err := os.WriteFile(path, []byte("v1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	o := defaults()
+	o.fromStart = true
+	s, err := newTailState(path, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.close()
+
+	if _, payload, err := s.o.framer.Frame(s.scan.r); err != nil || string(payload) != "v1" {
+		t.Fatalf("first read: %q, %v", payload, err)
+	}
+
+	// After our last read, the old file receives a final line and is then
+	// atomically replaced by renaming a new file over it.
+	old, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old.WriteString("v1 final\n")
+	old.Close()
+
+	tmpPath := filepath.Join(tmp, "config.log.tmp")
+	if err := os.WriteFile(tmpPath, []byte("v2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		t.Fatal(err)
+	}
+
+	if reopened, err := s.checkFileState(); err != nil || reopened {
+		t.Fatalf("checkFileState before drain = %v, %v; want no switch", reopened, err)
+	}
+	if _, payload, err := s.o.framer.Frame(s.scan.r); err != nil || string(payload) != "v1 final" {
+		t.Fatalf("drain read: %q, %v; want %q", payload, err, "v1 final")
+	}
+	if _, _, err := s.o.framer.Frame(s.scan.r); err != io.EOF {
+		t.Fatalf("expected EOF on drained file, got %v", err)
+	}
+	if err := s.rewind(0); err != nil {
+		t.Fatal(err)
+	}
+
+	if reopened, err := s.checkFileState(); err != nil || !reopened {
+		t.Fatalf("checkFileState after drain = %v, %v; want switch", reopened, err)
+	}
+	if _, payload, err := s.o.framer.Frame(s.scan.r); err != nil || string(payload) != "v2" {
+		t.Errorf("read after switch: %q, %v; want %q", payload, err, "v2")
+	}
+}
+
+func TestCheckFileStateDrainTimeout(t *testing.T) {
+	if !RotationDetectionSupported() {
+		t.Skip("no file identities on this platform")
+	}
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "app.log")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	o := defaults()
+	o.fromStart = true
+	o.rotationDrainTimeout = 100 * time.Millisecond
+	s, err := newTailState(path, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.close()
+
+	// A process keeps the rotated file open and goes on writing to it,
+	// a little more each time the tailer has caught up.
+	old, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer old.Close()
+	if err := os.Rename(path, filepath.Join(tmp, "app.log.1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	for {
+		old.WriteString("more\n")
+		reopened, err := s.checkFileState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reopened {
+			break
+		}
+		if time.Since(start) > time.Second {
+			t.Fatal("still draining the old file after a second")
+		}
+		for {
+			if _, _, err := s.o.framer.Frame(s.scan.r); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatal(err)
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if elapsed := time.Since(start); elapsed < o.rotationDrainTimeout {
+		t.Errorf("switched after %v, before the drain timeout", elapsed)
+	}
+	if got := s.t.Stats().DroppedOnRotation; got != int64(len("more\n")) {
+		t.Errorf("DroppedOnRotation = %d, want %d", got, len("more\n"))
+	}
+	if _, payload, err := s.o.framer.Frame(s.scan.r); err != nil || string(payload) != "new" {
+		t.Errorf("read after switch: %q, %v; want %q", payload, err, "new")
+	}
+}
+
+func TestCheckFileStateTruncatedAndRotated(t *testing.T) {
+	if !RotationDetectionSupported() {
+		t.Skip("no file identities on this platform")
+	}
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "app.log")
+	if err := os.WriteFile(path, []byte("old 1\nold 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	o := defaults()
+	o.fromStart = true
+	s, err := newTailState(path, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.close()
+
+	for {
+		if _, _, err := s.o.framer.Frame(s.scan.r); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.rewind(0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Within one poll, the old file is emptied, gets a stale line and is
+	// renamed away, and a new file takes its place.
+	if err := os.WriteFile(path, []byte("stale\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(path, filepath.Join(tmp, "app.log.1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("new 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if reopened, err := s.checkFileState(); err != nil || !reopened {
+		t.Fatalf("checkFileState = %v, %v; want switch", reopened, err)
+	}
+	if st := s.t.Stats(); st.Rotations != 1 || st.Truncations != 0 {
+		t.Errorf("got %d rotations, %d truncations, want 1, 0", st.Rotations, st.Truncations)
+	}
+	if _, payload, err := s.o.framer.Frame(s.scan.r); err != nil || string(payload) != "new 1" {
+		t.Errorf("read after switch: %q, %v; want %q", payload, err, "new 1")
+	}
+}
+
+func TestFollowAtomicRenameOver(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "config.log")
+
+	if err := os.WriteFile(path, []byte("v1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFromStart(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for len(got) < 3 {
+		if len(got) == 1 {
+			// Append to the old file, then replace it atomically.
+			old, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			old.WriteString("v1 final\n")
+			old.Close()
+
+			tmpPath := filepath.Join(tmp, "config.log.tmp")
+			if err := os.WriteFile(tmpPath, []byte("v2\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Rename(tmpPath, path); err != nil {
+				t.Fatal(err)
+			}
+		}
+		select {
+		case line := <-tailer.Lines():
+			got = append(got, line.Text)
+		case <-ctx.Done():
+			t.Fatalf("timed out after receiving %q", got)
+		}
+	}
+
+	if strings.Join(got, ",") != "v1,v1 final,v2" {
+		t.Errorf("got %q, want [v1 v1 final v2]", got)
+	}
+
+	cancel()
+	<-tailer.Done()
+}
+
+func TestFollowRestartOnShrink(t *testing.T) {
+	for _, recreate := range []bool{false, true} {
+		name := "truncate"
+		if recreate {
+			name = "recreate"
+		}
+		t.Run(name, func(t *testing.T) {
+			tmp := t.TempDir()
+			path := filepath.Join(tmp, "status.txt")
+
+			// The incomplete last line keeps our position well short of
+			// the end, so the rewritten file is smaller than the old one
+			// but still longer than the position.
+			if err := os.WriteFile(path, []byte("one\ntwo\nincomplete last line"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			tailer, err := Follow(ctx, path, WithFromStart(true), WithRestartOnShrink(true))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range []string{"one", "two"} {
+				select {
+				case line := <-tailer.Lines():
+					if line.Text != want {
+						t.Errorf("got %q, want %q", line.Text, want)
+					}
+				case <-ctx.Done():
+					t.Fatal("timed out waiting for first generation")
+				}
+			}
+			<-tailer.CaughtUp()
+			time.Sleep(200 * time.Millisecond)
+
+			next := []byte("new1\nnew2\n")
+			if recreate {
+				tmpPath := filepath.Join(tmp, "status.tmp")
+				if err := os.WriteFile(tmpPath, next, 0644); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Rename(tmpPath, path); err != nil {
+					t.Fatal(err)
+				}
+			} else if err := os.WriteFile(path, next, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, want := range []string{"new1", "new2"} {
+				select {
+				case line := <-tailer.Lines():
+					if line.Text != want {
+						t.Errorf("got %q, want %q", line.Text, want)
+					}
+				case <-ctx.Done():
+					t.Fatal("timed out waiting for second generation")
+				}
+			}
+
+			cancel()
+			<-tailer.Done()
+		})
+	}
+}
+
+func TestFollowManualPoll(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("one\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	trigger := make(chan struct{})
+	tailer, err := Follow(ctx, path, WithFromStart(true), WithManualPoll(trigger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line := <-tailer.Lines(); line.Text != "one" {
+		t.Errorf("got %q, want %q", line.Text, "one")
+	}
+	<-tailer.CaughtUp()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.WriteString("two\n")
+
+	// Without a trigger the tailer never reads again.
+	select {
+	case line := <-tailer.Lines():
+		t.Fatalf("got %q before the trigger", line.Text)
+	default:
+	}
+
+	trigger <- struct{}{}
+	select {
+	case line := <-tailer.Lines():
+		if line.Text != "two" {
+			t.Errorf("got %q, want %q", line.Text, "two")
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+
+	cancel()
+	<-tailer.Done()
+}
+
+func TestFollowChunksStraddlingBuffer(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	var want []string
+	var content strings.Builder
+	for i := 0; i < 40; i++ {
+		line := strings.Repeat(string(rune('a'+i%26)), i%37)
+		want = append(want, fmt.Sprintf("%d:%s", i, line))
+		content.WriteString(want[i] + "\n")
+	}
+	data := content.String()
+
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// With the smallest buffer, chunks of odd sizes end both inside and
+	// beyond what the reader has buffered at each EOF.
+	trigger := make(chan struct{})
+	tailer, err := Follow(ctx, path, WithBufSize(16), WithManualPoll(trigger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-tailer.CaughtUp()
+
+	var got []string
+	sizes := []int{7, 13, 17, 31, 5, 1, 23}
+	for i := 0; len(data) > 0; i++ {
+		n := min(sizes[i%len(sizes)], len(data))
+		f.WriteString(data[:n])
+		data = data[n:]
+		select {
+		case trigger <- struct{}{}:
+		case <-ctx.Done():
+			t.Fatal("timed out triggering a read")
+		}
+	drain:
+		for {
+			select {
+			case line := <-tailer.Lines():
+				got = append(got, line.Text)
+			default:
+				break drain
+			}
+		}
+	}
+	for len(got) < len(want) {
+		select {
+		case trigger <- struct{}{}:
+		case line := <-tailer.Lines():
+			got = append(got, line.Text)
+		case <-ctx.Done():
+			t.Fatalf("timed out after %d lines", len(got))
+		}
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("line %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+	if st := tailer.Stats(); st.BytesRead != int64(content.Len()) {
+		t.Errorf("BytesRead = %d, want %d", st.BytesRead, content.Len())
+	}
+
+	cancel()
+	<-tailer.Done()
+}
+
+func TestFollowWithoutRotationDetection(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("one\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	trigger := make(chan struct{})
+	tailer, err := Follow(ctx, path,
+		WithFromStart(true),
+		WithManualPoll(trigger),
+		WithRotationDetection(false),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line := <-tailer.Lines(); line.Text != "one" {
+		t.Errorf("got %q, want %q", line.Text, "one")
+	}
+
+	// Replace the file; the tailer keeps reading the original handle.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("new file\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	trigger <- struct{}{}
+	trigger <- struct{}{}
+	f.WriteString("two\n")
+
+	for {
+		select {
+		case line := <-tailer.Lines():
+			if line.Text != "two" {
+				t.Fatalf("got %q, want %q", line.Text, "two")
+			}
+			if st := tailer.Stats(); st.Rotations != 0 {
+				t.Errorf("Rotations = %d, want 0", st.Rotations)
+			}
+			cancel()
+			<-tailer.Done()
+			return
+		case trigger <- struct{}{}:
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+	}
+}
+
+func TestFollowAppendOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	trigger := make(chan struct{})
+	tailer, err := Follow(ctx, path,
+		WithFromStart(true),
+		WithManualPoll(trigger),
+		WithAppendOnly(true),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"one", "two"} {
+		if got := nextLine(ctx, t, tailer); got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	}
+
+	// A truncation goes unnoticed: the tailer goes on reading at its
+	// old offset, 8, skipping what the new content has before it.
+	if err := os.WriteFile(path, []byte("x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	trigger <- struct{}{}
+	trigger <- struct{}{}
+	appendLine(t, path, "older")
+	appendLine(t, path, "read")
+	trigger <- struct{}{}
+	if got := nextLine(ctx, t, tailer); got != "read" {
+		t.Errorf("got %q, want %q", got, "read")
+	}
+	if st := tailer.Stats(); st.Truncations != 0 {
+		t.Errorf("Truncations = %d, want 0", st.Truncations)
+	}
+
+	lines, err := ReadAll(ctx, path, WithAppendOnly(true))
+	if err != nil || len(lines) != 3 {
+		t.Errorf("ReadAll: got %d lines, %v; want 3", len(lines), err)
+	}
+}
+
+// BenchmarkPoll measures a poll at the end of an idle file. By default it
+// checks the file state with a seek, a stat of the handle and stats of
+// the path; WithAppendOnly skips those checks.
+func BenchmarkPoll(b *testing.B) {
+	for _, appendOnly := range []bool{false, true} {
+		b.Run(fmt.Sprintf("appendOnly=%v", appendOnly), func(b *testing.B) {
+			path := filepath.Join(b.TempDir(), "test.log")
+			if err := os.WriteFile(path, []byte("line\n"), 0644); err != nil {
+				b.Fatal(err)
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			trigger := make(chan struct{})
+			tailer, err := Follow(ctx, path, WithManualPoll(trigger), WithAppendOnly(appendOnly))
+			if err != nil {
+				b.Fatal(err)
+			}
+			<-tailer.CaughtUp()
+
+			// Each send is taken once the previous poll is done.
+			b.ResetTimer()
+			for range b.N {
+				trigger <- struct{}{}
+			}
+			b.StopTimer()
+			cancel()
+			<-tailer.Done()
+		})
+	}
+}
+
+func TestPathTruncated(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("first line\nsecond line\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	o := defaults()
+	o.pathTruncationCheck = true
+	s, err := newTailState(path, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.close()
+
+	pos, err := s.position()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.pathTruncated(pos) {
+		t.Error("pathTruncated before truncation")
+	}
+
+	if err := os.Truncate(path, 5); err != nil {
+		t.Fatal(err)
+	}
+	if !s.pathTruncated(pos) {
+		t.Error("pathTruncated = false after truncation")
+	}
+
+	// A shorter file renamed over the path is a rotation, not a
+	// truncation.
+	other := filepath.Join(tmp, "other.log")
+	if err := os.WriteFile(other, []byte("x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(other, path); err != nil {
+		t.Fatal(err)
+	}
+	if RotationDetectionSupported() && s.pathTruncated(pos) {
+		t.Error("pathTruncated = true for a replaced file")
+	}
+}
+
+func TestRotationDetectionSupported(t *testing.T) {
+	info, err := os.Stat(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	known := getFileIdentity(info) != (fileIdentity{})
+	if got := RotationDetectionSupported(); got != known {
+		t.Errorf("RotationDetectionSupported() = %v, but identities known = %v", got, known)
+	}
+}
+
+func TestZeroPollIntervalDoesNotSpin(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	o := defaults()
+	WithPollInterval(0)(&o)
+	if o.pollInterval != minPollInterval {
+		t.Fatalf("pollInterval = %v, want %v", o.pollInterval, minPollInterval)
+	}
+
+	s, err := newTailState(path, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.close()
+
+	// Each wait at EOF takes at least the minimum interval instead of
+	// returning at once.
+	const polls = 20
+	start := time.Now()
+	for i := 0; i < polls; i++ {
+		s.waitForData(context.Background())
+	}
+	if elapsed := time.Since(start); elapsed < polls*minPollInterval {
+		t.Errorf("%d polls took %v, want at least %v", polls, elapsed, polls*minPollInterval)
+	}
+}
+
+func TestFollowConcurrentAppendNoSpuriousTruncation(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithPollInterval(time.Millisecond), WithBufSize(64))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Several O_APPEND writers hammer the file while the tailer keeps
+	// hitting EOF and checking for truncation.
+	const writers, perWriter = 4, 2000
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer f.Close()
+			for n := 0; n < perWriter; n++ {
+				fmt.Fprintf(f, "w%d %d\n", w, n)
+			}
+		}()
+	}
+
+	next := make(map[string]int)
+	for received := 0; received < writers*perWriter; received++ {
+		select {
+		case line := <-tailer.Lines():
+			writer, n, _ := strings.Cut(line.Text, " ")
+			if want := fmt.Sprint(next[writer]); n != want {
+				t.Fatalf("%s: got line %s, want %s", writer, n, want)
+			}
+			next[writer]++
+		case <-ctx.Done():
+			t.Fatalf("timed out after %d lines", received)
+		}
+	}
+	wg.Wait()
+
+	if st := tailer.Stats(); st.Truncations != 0 {
+		t.Errorf("Truncations = %d under concurrent appends, want 0", st.Truncations)
+	}
+
+	cancel()
+	<-tailer.Done()
+}
+
+func TestFollowPartialAtBufferBoundary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+
+	// Both the complete line and the incomplete one fill the 16-byte
+	// buffer exactly, so the read of the second one returns a full
+	// buffer of data together with EOF.
+	const first, partial = "0123456789abcde\n", "partial-16-bytes"
+	if err := os.WriteFile(path, []byte(first+partial), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFromStart(true), WithBufSize(16), WithPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := nextLine(ctx, t, tailer); got != "0123456789abcde" {
+		t.Fatalf("got %q, want %q", got, "0123456789abcde")
+	}
+	<-tailer.CaughtUp()
+
+	// The incomplete line is neither delivered nor counted, and the
+	// position stays at its start however often it is read again.
+	time.Sleep(50 * time.Millisecond)
+	if n := tailer.Stats().BytesRead; n != int64(len(first)) {
+		t.Errorf("BytesRead = %d, want %d", n, len(first))
+	}
+	tok, err := tailer.Position()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.Offset() != int64(len(first)) {
+		t.Errorf("position = %d, want %d", tok.Offset(), len(first))
+	}
+
+	appendLine(t, path, "")
+	if got := nextLine(ctx, t, tailer); got != partial {
+		t.Errorf("got %q, want %q", got, partial)
+	}
+	if n := tailer.Stats().BytesRead; n != int64(len(first)+len(partial)+1) {
+		t.Errorf("BytesRead = %d, want %d", n, len(first)+len(partial)+1)
+	}
+	select {
+	case line := <-tailer.Lines():
+		t.Errorf("unexpected extra line %q", line.Text)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestStopAtEOFPartialAtBufferBoundary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	const first, partial = "0123456789abcde\n", "partial-16-bytes"
+	if err := os.WriteFile(path, []byte(first+partial), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFromStart(true), WithBufSize(16), WithStopAtEOF(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []Line
+	for line := range tailer.Lines() {
+		lines = append(lines, line)
+	}
+	if len(lines) != 2 || lines[1].Text != partial || !lines[1].Partial {
+		t.Fatalf("got %+v, want the complete line and %q as a partial one", lines, partial)
+	}
+	if n := tailer.Stats().BytesRead; n != int64(len(first)+len(partial)) {
+		t.Errorf("BytesRead = %d, want %d", n, len(first)+len(partial))
+	}
+}
@@ -0,0 +1,117 @@
+//go:build windows
+
+package tailf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestOpenSharedAllowsRotation(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "app.log")
+	if err := os.WriteFile(path, []byte("line\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := openShared(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The writer can rename the open file away and delete it.
+	moved := filepath.Join(tmp, "app.log.1")
+	if err := os.Rename(path, moved); err != nil {
+		t.Fatalf("rename of an open file: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pathInfo, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !getFileIdentity(info).replacedBy(getFileIdentity(pathInfo)) {
+		t.Error("new file at the path not seen as a replacement")
+	}
+	if err := os.Remove(moved); err != nil {
+		t.Fatalf("removal of an open file: %v", err)
+	}
+}
+
+func TestFollowRotationWindows(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "app.log")
+	if err := os.WriteFile(path, []byte("old\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := append(PresetWindowsEventText(), WithFromStart(true), WithPollInterval(10*time.Millisecond))
+	tailer, err := Follow(ctx, path, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := nextLine(ctx, t, tailer); got != "old" {
+		t.Fatalf("got %q, want %q", got, "old")
+	}
+
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("new\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := nextLine(ctx, t, tailer); got != "new" {
+		t.Errorf("got %q, want %q", got, "new")
+	}
+	if st := tailer.Stats(); st.Rotations != 1 {
+		t.Errorf("Rotations = %d, want 1", st.Rotations)
+	}
+}
+
+func TestFollowContentsWindowsExport(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "events.txt")
+	export := func(text string) {
+		t.Helper()
+		// Exports are written to a new file that replaces the old one.
+		tmpPath := filepath.Join(tmp, "events.tmp")
+		data := append([]byte{0xFF, 0xFE}, utf16Bytes(text, false)...)
+		if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmpPath, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	export("Information\tService started\r\n")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	contents, err := FollowContents(ctx, path, WithTextDecoding(true), WithPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := SplitLines(<-contents); !slices.Equal(got, []string{"Information\tService started"}) {
+		t.Errorf("got %q", got)
+	}
+
+	export("Information\tService started\r\nError\tDisk full\r\n")
+	want := []string{"Information\tService started", "Error\tDisk full"}
+	if got := SplitLines(<-contents); !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
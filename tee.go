@@ -0,0 +1,22 @@
+package tailf
+
+// tee writes l to the [WithTee] writer, if there is one, once l has been
+// accepted for delivery; markers are not written. It reports false if
+// the write failed and the failure is fatal, in which case s.fatal is
+// set.
+func (s *tailState) tee(l Line) bool {
+	if s.o.tee == nil || l.Marker != NoMarker {
+		return true
+	}
+	buf := make([]byte, 0, len(l.Text)+1)
+	buf = append(buf, l.Text...)
+	buf = append(buf, '\n')
+	if _, err := s.o.tee.Write(buf); err != nil {
+		if s.o.teeFatal {
+			s.fatal = s.fail("tee", err)
+			return false
+		}
+		s.t.updateStats(func(st *Stats) { st.TeeErrors++ })
+	}
+	return true
+}
@@ -0,0 +1,110 @@
+package tailf
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestTee(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("keep one\ndrop\nkeep two\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var out strings.Builder
+	lines, err := ReadAll(ctx, path,
+		WithFilter(func(l Line) bool { return strings.HasPrefix(l.Text, "KEEP") }),
+		WithTransform(strings.ToUpper),
+		WithTee(&out, true),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 2 {
+		t.Errorf("got %d lines, want 2", len(lines))
+	}
+	if want := "KEEP ONE\nKEEP TWO\n"; out.String() != want {
+		t.Errorf("tee got %q, want %q", out.String(), want)
+	}
+}
+
+func TestTeeOverflowDrop(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	writeNumbered(t, path, 2*lineBuffer)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var out strings.Builder
+	tailer, err := Follow(ctx, path,
+		WithFromStart(true),
+		WithOverflowPolicy(OverflowDrop),
+		WithTee(&out, true),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-tailer.CaughtUp()
+	cancel()
+	<-tailer.Done()
+
+	// Only the lines that fit in the channel were delivered, and teed.
+	var delivered strings.Builder
+	for line := range tailer.Lines() {
+		delivered.WriteString(line.Text + "\n")
+	}
+	if got := strings.Count(out.String(), "\n"); got != lineBuffer {
+		t.Errorf("tee got %d lines, want %d", got, lineBuffer)
+	}
+	if out.String() != delivered.String() {
+		t.Errorf("tee and delivered lines differ:\n%q\n%q", out.String(), delivered.String())
+	}
+}
+
+func TestTeeErrors(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// Ignored errors are counted and lines are still delivered.
+	tailer, err := Follow(ctx, path, WithFromStart(true), WithStopAtEOF(true), WithTee(failingWriter{}, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for range tailer.Lines() {
+		n++
+	}
+	if st := tailer.Stats(); n != 2 || st.TeeErrors != 2 || tailer.Err() != nil {
+		t.Errorf("got %d lines, %d tee errors, err %v; want 2, 2, nil", n, st.TeeErrors, tailer.Err())
+	}
+
+	// A fatal error stops the tailer.
+	_, err = ReadAll(ctx, path, WithTee(failingWriter{}, true))
+	var te *TailError
+	if !errors.As(err, &te) || te.Op != "tee" {
+		t.Errorf("err = %v, want a tee TailError", err)
+	}
+}
@@ -0,0 +1,292 @@
+package tailf
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// ErrInvalidToken is returned by [Token.UnmarshalText] for text that was
+// not produced by [Token.MarshalText] or has been altered.
+var ErrInvalidToken = errors.New("tailf: invalid resume token")
+
+// ErrTokenMismatch is returned by [Follow] under [WithResumeToken] with
+// [ResumeError] when the token does not describe the file found at the
+// path.
+var ErrTokenMismatch = errors.New("resume token does not match file")
+
+// errGzipPosition is returned by [Tailer.Position] under [WithGzipInput].
+var errGzipPosition = errors.New("tailf: no position in compressed input")
+
+// errHeldPosition is returned by [Tailer.Position] while lines read from
+// a file the tailer has since left are held by [WithReorder] or queued
+// by [WithSpillToDisk].
+var errHeldPosition = errors.New("tailf: lines from a previous file not yet delivered")
+
+// tokenVersion is the first byte of an encoded Token.
+const tokenVersion = 1
+
+// Token is an opaque position in a tailed file, as returned by
+// [Tailer.Position], from which a later tailer can resume with
+// [WithResumeToken]. It records the identity of the file, the number of
+// rotations and truncations seen, the offset of the next line and the
+// number of lines delivered before it. Its text form, from
+// [Token.MarshalText], is safe to store and carries a checksum.
+type Token struct {
+	id         string
+	generation int64
+	offset     int64
+	seq        int64
+}
+
+// IsZero reports whether t is the zero Token, which resumes nothing.
+func (t Token) IsZero() bool {
+	return t == Token{}
+}
+
+// Offset returns the byte offset in the file at which the token resumes.
+func (t Token) Offset() int64 {
+	return t.offset
+}
+
+// String returns the text form of t.
+func (t Token) String() string {
+	b, _ := t.MarshalText()
+	return string(b)
+}
+
+// MarshalText encodes t as URL-safe base64 text.
+func (t Token) MarshalText() ([]byte, error) {
+	b := []byte{tokenVersion}
+	b = binary.AppendUvarint(b, uint64(len(t.id)))
+	b = append(b, t.id...)
+	b = binary.AppendVarint(b, t.generation)
+	b = binary.AppendVarint(b, t.offset)
+	b = binary.AppendVarint(b, t.seq)
+	b = binary.BigEndian.AppendUint32(b, crc32.ChecksumIEEE(b))
+	return base64.RawURLEncoding.AppendEncode(nil, b), nil
+}
+
+// UnmarshalText decodes text produced by [Token.MarshalText]. It returns
+// an error wrapping [ErrInvalidToken] if the text is malformed, was
+// written by an incompatible version or fails its checksum.
+func (t *Token) UnmarshalText(text []byte) error {
+	b, err := base64.RawURLEncoding.AppendDecode(nil, text)
+	if err != nil || len(b) < 5 {
+		return fmt.Errorf("%w: malformed", ErrInvalidToken)
+	}
+	body, sum := b[:len(b)-4], binary.BigEndian.Uint32(b[len(b)-4:])
+	if crc32.ChecksumIEEE(body) != sum {
+		return fmt.Errorf("%w: checksum mismatch", ErrInvalidToken)
+	}
+	if body[0] != tokenVersion {
+		return fmt.Errorf("%w: unknown version %d", ErrInvalidToken, body[0])
+	}
+
+	r := tokenReader{b: body[1:]}
+	n := r.uvarint()
+	id := r.bytes(n)
+	tok := Token{
+		id:         string(id),
+		generation: r.varint(),
+		offset:     r.varint(),
+		seq:        r.varint(),
+	}
+	if r.err != nil || len(r.b) != 0 || tok.offset < 0 {
+		return fmt.Errorf("%w: malformed", ErrInvalidToken)
+	}
+	*t = tok
+	return nil
+}
+
+// tokenReader decodes the fields of an encoded Token, remembering the
+// first error.
+type tokenReader struct {
+	b   []byte
+	err error
+}
+
+func (r *tokenReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.b)
+	if n <= 0 {
+		r.err = io.ErrUnexpectedEOF
+		return 0
+	}
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *tokenReader) varint() int64 {
+	v, n := binary.Varint(r.b)
+	if n <= 0 {
+		r.err = io.ErrUnexpectedEOF
+		return 0
+	}
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *tokenReader) bytes(n uint64) []byte {
+	if r.err != nil || n > uint64(len(r.b)) {
+		r.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v
+}
+
+// ResumeFallback says what [WithResumeToken] does when the token does not
+// describe the file found at the path, such as after it was rotated away,
+// or the file is now shorter than the token's offset.
+type ResumeFallback int
+
+const (
+	// ResumeFromEnd follows the file from its end, as if no token had
+	// been given and the tailer started from the end.
+	ResumeFromEnd ResumeFallback = iota
+
+	// ResumeFromStart reads the file from its start.
+	ResumeFromStart
+
+	// ResumeError makes [Follow] fail with [ErrTokenMismatch].
+	ResumeError
+)
+
+// Position returns a token for the current position in the file: the
+// offset of the first line not yet handed to the [Tailer.Lines] channel.
+// A line being delivered when Position is called lies after it, and so
+// do lines held back by [WithReorder] or queued by [WithSpillToDisk].
+// Lines still buffered in the channel (see [Tailer.Pending]) lie before
+// it and are not read again on resume, so a consumer that stores the
+// token to resume after a crash should take it when it has received
+// everything it needs.
+//
+// Positions inside compressed input are not supported and return an
+// error under [WithGzipInput]. So does a position taken while lines read
+// from a file the tailer has since rotated away from are held or queued,
+// as a token cannot point into that file. It returns [ErrStopped] if the
+// tailer is no longer running.
+func (t *Tailer) Position() (Token, error) {
+	var tok Token
+	err := t.do(func(s *tailState) error {
+		if s.o.gzip {
+			return errGzipPosition
+		}
+		var err error
+		tok, err = s.token()
+		return err
+	})
+	return tok, err
+}
+
+// linePos is where the record of a line starts: its offset in the file
+// of the given rotation generation.
+type linePos struct {
+	generation int64
+	offset     int64
+}
+
+// token returns the Token for the current position, before the line
+// being delivered and any held or queued ones.
+func (s *tailState) token() (Token, error) {
+	pos := s.readPos()
+	older := func(at linePos) error {
+		if at.generation != s.generation {
+			return errHeldPosition
+		}
+		pos.offset = min(pos.offset, at.offset)
+		return nil
+	}
+	if s.reordering != nil {
+		for _, h := range s.reordering.held {
+			if err := older(h.at); err != nil {
+				return Token{}, err
+			}
+		}
+	}
+	if s.spill != nil {
+		for _, at := range s.spill.at {
+			if err := older(at); err != nil {
+				return Token{}, err
+			}
+		}
+	}
+	return Token{
+		id:         s.identity(),
+		generation: s.generation,
+		offset:     pos.offset,
+		seq:        s.seqBase + s.t.Stats().Lines,
+	}, nil
+}
+
+// readPos returns the position of the line being delivered, or of the
+// next one to be read if none is.
+func (s *tailState) readPos() linePos {
+	return linePos{s.generation, s.readOffset() - int64(s.inFlight)}
+}
+
+// identity returns the identity of the current file recorded in tokens:
+// its [WithIdentityFunc] identity if set, or else its inode number, or
+// "" if neither is known.
+func (s *tailState) identity() string {
+	if s.o.identity != nil {
+		if s.customID == "" {
+			return ""
+		}
+		return "id:" + s.customID
+	}
+	return s.fileID.token()
+}
+
+// readOffset returns the offset of the next byte to be framed, following
+// the replay of [WithMmap] while it lasts.
+func (s *tailState) readOffset() int64 {
+	if s.mapping {
+		return s.mapOff
+	}
+	return s.offset()
+}
+
+// resume positions the newly opened file at the [WithResumeToken] token,
+// if it describes this file, or as its fallback says otherwise. A token
+// without an identity, as on platforms without one, is trusted.
+func (s *tailState) resume(size int64) error {
+	tok := s.o.resumeToken
+	id := s.identity()
+	if (tok.id == "" || id == "" || tok.id == id) && tok.offset <= size {
+		if err := s.seekTo(tok.offset); err != nil {
+			return err
+		}
+		s.generation = tok.generation
+		s.seqBase = tok.seq
+		return nil
+	}
+	if succ := openSuccessor(s.path, s.o); succ != nil {
+		s.useSuccessor(succ)
+		return s.seekTo(0)
+	}
+
+	switch s.o.resumeFallback {
+	case ResumeFromStart:
+		return s.seekTo(0)
+	case ResumeError:
+		return ErrTokenMismatch
+	default:
+		return s.seekTo(size)
+	}
+}
+
+// seekTo moves the file to offset, which is at the start of a line, and
+// discards buffered input.
+func (s *tailState) seekTo(offset int64) error {
+	if _, err := s.file.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	s.resetReader()
+	s.scan.torn = false
+	return nil
+}
@@ -0,0 +1,363 @@
+package tailf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenText(t *testing.T) {
+	tok := Token{id: "ino:42", generation: 3, offset: 1 << 40, seq: 17}
+	text, err := tok.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got Token
+	if err := got.UnmarshalText(text); err != nil {
+		t.Fatal(err)
+	}
+	if got != tok {
+		t.Errorf("got %+v, want %+v", got, tok)
+	}
+
+	// Any altered byte fails the checksum or the decoding.
+	for i := range text {
+		bad := []byte(string(text))
+		bad[i] ^= 1
+		if err := got.UnmarshalText(bad); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("byte %d altered: got %v, want ErrInvalidToken", i, err)
+		}
+	}
+	for _, bad := range []string{"", "!!", "AAAA"} {
+		if err := got.UnmarshalText([]byte(bad)); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%q: got %v, want ErrInvalidToken", bad, err)
+		}
+	}
+}
+
+func TestPositionResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, []byte("a\nb\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	first, err := Follow(ctx, path, WithFromStart(true), WithPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"a", "b"} {
+		if line := <-first.Lines(); line.Text != want {
+			t.Fatalf("got %q, want %q", line.Text, want)
+		}
+	}
+	<-first.CaughtUp()
+	tok, err := first.Position()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.Offset() != 4 || tok.seq != 2 {
+		t.Errorf("got offset %d, seq %d, want 4, 2", tok.Offset(), tok.seq)
+	}
+
+	// Store the token as text, as a checkpoint would.
+	text, err := tok.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded Token
+	if err := loaded.UnmarshalText(text); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintln(f, "c")
+	f.Close()
+
+	second, err := Follow(ctx, path, WithResumeToken(loaded, ResumeError), WithPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line := <-second.Lines(); line.Text != "c" {
+		t.Errorf("got %q, want %q", line.Text, "c")
+	}
+	next, err := second.Position()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.seq != 3 || next.generation != tok.generation {
+		t.Errorf("got seq %d, generation %d, want 3, %d", next.seq, next.generation, tok.generation)
+	}
+}
+
+func TestPositionExcludesLineInFlight(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	var content strings.Builder
+	for i := range lineBuffer + 5 {
+		fmt.Fprintf(&content, "line %d\n", i)
+	}
+	if err := os.WriteFile(path, []byte(content.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFromStart(true), WithPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for tailer.Pending() < lineBuffer {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The channel is full and the next line is waiting to be sent.
+	tok, err := tailer.Position()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resumed, err := Follow(ctx, path, WithResumeToken(tok, ResumeError))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprintf("line %d", lineBuffer)
+	if line := <-resumed.Lines(); line.Text != want {
+		t.Errorf("got %q, want %q", line.Text, want)
+	}
+}
+
+func TestResumeTokenMismatch(t *testing.T) {
+	if !RotationDetectionSupported() {
+		t.Skip("no file identities on this platform")
+	}
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tailer, err := Follow(ctx, path, WithFromStart(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-tailer.Lines()
+	<-tailer.CaughtUp()
+	tok, err := tailer.Position()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Replace the file with a new one, as a rotation would.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte("new 1\nnew 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Follow(ctx, path, WithResumeToken(tok, ResumeError)); !errors.Is(err, ErrTokenMismatch) {
+		t.Fatalf("got %v, want ErrTokenMismatch", err)
+	}
+
+	fromStart, err := Follow(ctx, path, WithResumeToken(tok, ResumeFromStart))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line := <-fromStart.Lines(); line.Text != "new 1" {
+		t.Errorf("ResumeFromStart: got %q, want %q", line.Text, "new 1")
+	}
+
+	fromEnd, err := Follow(ctx, path, WithResumeToken(tok, ResumeFromEnd), WithPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-fromEnd.CaughtUp()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintln(f, "new 3")
+	f.Close()
+	if line := <-fromEnd.Lines(); line.Text != "new 3" {
+		t.Errorf("ResumeFromEnd: got %q, want %q", line.Text, "new 3")
+	}
+}
+
+func TestRotationEventPosition(t *testing.T) {
+	if !RotationDetectionSupported() {
+		t.Skip("no file identities on this platform")
+	}
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, []byte("old 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Stop right at the rotation, as a crash would, keeping the token
+	// a checkpoint would have stored at the boundary.
+	tailCtx, stop := context.WithCancel(ctx)
+	rotated := make(chan Token, 1)
+	tailer, err := Follow(tailCtx, path,
+		WithFromStart(true),
+		WithPollInterval(10*time.Millisecond),
+		WithEventHandler(func(e Event) {
+			if e.Type == EventRotated {
+				rotated <- e.Position
+				stop()
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line := <-tailer.Lines(); line.Text != "old 1" {
+		t.Fatalf("got %q, want %q", line.Text, "old 1")
+	}
+	<-tailer.CaughtUp()
+	before, err := tailer.Position()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The old file gets a last line before it is renamed away.
+	appendLine(t, path, "old 2")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("new 1\nnew 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var tok Token
+	select {
+	case tok = <-rotated:
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the rotation")
+	}
+	<-tailer.Done()
+	var got []string
+	for line := range tailer.Lines() {
+		got = append(got, line.Text)
+	}
+	if len(got) != 1 || got[0] != "old 2" {
+		t.Errorf("old file: got %q, want it drained to %q", got, "old 2")
+	}
+	if tok.Offset() != 0 || tok.generation != before.generation+1 || tok.seq != 2 {
+		t.Errorf("got offset %d, generation %d, seq %d, want 0, %d, 2",
+			tok.Offset(), tok.generation, tok.seq, before.generation+1)
+	}
+
+	resumed, err := Follow(ctx, path, WithResumeToken(tok, ResumeError))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"new 1", "new 2"} {
+		if line := <-resumed.Lines(); line.Text != want {
+			t.Errorf("resumed: got %q, want %q", line.Text, want)
+		}
+	}
+}
+
+func TestResumeCompressedSuccessor(t *testing.T) {
+	if !RotationDetectionSupported() {
+		t.Skip("no file identities on this platform")
+	}
+	for _, missing := range []bool{false, true} {
+		t.Run(fmt.Sprintf("missing=%v", missing), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			if err := os.WriteFile(path, []byte("old 1\nold 2\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			first, err := Follow(ctx, path, WithFromStart(true))
+			if err != nil {
+				t.Fatal(err)
+			}
+			<-first.Lines()
+			<-first.Lines()
+			<-first.CaughtUp()
+			tok, err := first.Position()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The file gets another line, is rotated away and compressed.
+			appendLine(t, path, "old 3")
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var gz bytes.Buffer
+			zw := gzip.NewWriter(&gz)
+			zw.Write(data)
+			zw.Close()
+			if err := os.WriteFile(path+".gz", gz.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Remove(path); err != nil {
+				t.Fatal(err)
+			}
+			if !missing {
+				if err := os.WriteFile(path, []byte("new 1\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			resumed, err := Follow(ctx, path,
+				WithResumeToken(tok, ResumeError),
+				WithCompressedResume(func(path string) string { return path + ".gz" }),
+				WithPollInterval(10*time.Millisecond),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if line := <-resumed.Lines(); line.Text != "old 3" {
+				t.Errorf("got %q, want %q", line.Text, "old 3")
+			}
+			if missing {
+				if err := os.WriteFile(path, []byte("new 1\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if line := <-resumed.Lines(); line.Text != "new 1" {
+				t.Errorf("got %q, want %q", line.Text, "new 1")
+			}
+		})
+	}
+
+	// Without a successor the fallback applies.
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tok := Token{id: "ino:1", offset: 4}
+	_, err := Follow(context.Background(), path,
+		WithResumeToken(tok, ResumeError),
+		WithCompressedResume(func(path string) string { return path + ".gz" }),
+	)
+	if !errors.Is(err, ErrTokenMismatch) {
+		t.Errorf("got %v, want ErrTokenMismatch", err)
+	}
+}
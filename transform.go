@@ -0,0 +1,28 @@
+package tailf
+
+import "strings"
+
+// expandTabs replaces tabs in s with spaces up to the next multiple of
+// width columns.
+func expandTabs(s string, width int) string {
+	if strings.IndexByte(s, '\t') < 0 {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s) + width)
+	col := 0
+	for _, r := range s {
+		if r == '\t' {
+			n := width - col%width
+			for i := 0; i < n; i++ {
+				b.WriteByte(' ')
+			}
+			col += n
+			continue
+		}
+		b.WriteRune(r)
+		col++
+	}
+	return b.String()
+}
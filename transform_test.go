@@ -0,0 +1,70 @@
+package tailf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpandTabs(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		want  string
+	}{
+		{"no tabs", 4, "no tabs"},
+		{"\tx", 4, "    x"},
+		{"a\tb", 4, "a   b"},
+		{"abcd\te", 4, "abcd    e"},
+		{"abc\t\tz", 4, "abc     z"},
+		{"é\tx", 4, "é   x"},
+		{"a\tb", 8, "a       b"},
+	}
+	for _, tt := range tests {
+		if got := expandTabs(tt.in, tt.width); got != tt.want {
+			t.Errorf("expandTabs(%q, %d) = %q, want %q", tt.in, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestFollowExpandTabsBeforeTransform(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.log")
+
+	if err := os.WriteFile(path, []byte("id\tname\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// The transform sees the expanded text, so it finds no tab.
+	tailer, err := Follow(ctx, path,
+		WithFromStart(true),
+		WithExpandTabs(4),
+		WithTransform(func(s string) string {
+			if strings.Contains(s, "\t") {
+				return "tab seen"
+			}
+			return "[" + s + "]"
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case line := <-tailer.Lines():
+		if line.Text != "[id  name]" {
+			t.Errorf("got %q, want %q", line.Text, "[id  name]")
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+
+	cancel()
+	<-tailer.Done()
+}
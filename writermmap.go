@@ -0,0 +1,57 @@
+package tailf
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// nulReader reads a file that its writer fills through a memory mapping,
+// for [WithWriterMmap]. The first NUL byte marks the end of the data
+// written so far: a read stops short of it with io.EOF, and the file is
+// positioned back at it, to read it again once it has been written.
+type nulReader struct {
+	file *os.File
+}
+
+func (r nulReader) Read(p []byte) (int, error) {
+	n, err := r.file.Read(p)
+	i := bytes.IndexByte(p[:n], 0)
+	if i < 0 {
+		return n, err
+	}
+	if _, err := r.file.Seek(int64(i-n), io.SeekCurrent); err != nil {
+		return i, err
+	}
+	return i, io.EOF
+}
+
+// fileSource returns the reader for the content of file: the file
+// itself, or a nulReader under [WithWriterMmap].
+func fileSource(file *os.File, o *options) io.Reader {
+	if o.writerMmap {
+		return nulReader{file}
+	}
+	return file
+}
+
+// writtenSize returns the length of the written part of a file of size
+// bytes under [WithWriterMmap]: the offset of its first NUL byte. The
+// written part holds no NUL bytes and the rest nothing else, so a binary
+// search finds it.
+func writtenSize(file *os.File, size int64) (int64, error) {
+	var b [1]byte
+	lo, hi := int64(0), size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		if _, err := file.ReadAt(b[:], mid); err != nil {
+			return 0, err
+		}
+		if b[0] == 0 {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo, nil
+}
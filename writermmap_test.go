@@ -0,0 +1,70 @@
+package tailf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFollowWriterMmap(t *testing.T) {
+	for _, fromStart := range []bool{true, false} {
+		path := filepath.Join(t.TempDir(), "test.db.log")
+
+		// The writer sizes the file up front and fills it gradually.
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if err := f.Truncate(64 << 10); err != nil {
+			t.Fatal(err)
+		}
+		off := int64(0)
+		write := func(s string) {
+			t.Helper()
+			if _, err := f.WriteAt([]byte(s), off); err != nil {
+				t.Fatal(err)
+			}
+			off += int64(len(s))
+		}
+		write("line 1\n")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		tailer, err := Follow(ctx, path,
+			WithFromStart(fromStart),
+			WithWriterMmap(true),
+			WithBufSize(16),
+			WithPollInterval(10*time.Millisecond),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		<-tailer.CaughtUp()
+
+		write("line 2\npar")
+		time.Sleep(50 * time.Millisecond)
+		write("tial\n")
+
+		want := []string{"line 2", "partial"}
+		if fromStart {
+			want = append([]string{"line 1"}, want...)
+		}
+		for _, w := range want {
+			select {
+			case line := <-tailer.Lines():
+				if line.Text != w {
+					t.Errorf("fromStart=%v: got %q, want %q", fromStart, line.Text, w)
+				}
+			case <-ctx.Done():
+				t.Fatalf("fromStart=%v: timed out waiting for %q", fromStart, w)
+			}
+		}
+		if st := tailer.Stats(); st.Reopens != 0 || st.Truncations != 0 {
+			t.Errorf("fromStart=%v: got %d reopens, %d truncations", fromStart, st.Reopens, st.Truncations)
+		}
+	}
+}